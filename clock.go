@@ -0,0 +1,104 @@
+package otelemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Clock is the source of the times stamped on the telemetry, see Config.Clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// metricStamper replaces the times of the collected metrics with those of a
+// Clock: the collection time, and as start time the creation of the stamper
+// for cumulative points and the previous collection for delta points.
+type metricStamper struct {
+	clock Clock
+
+	mu    sync.Mutex
+	start time.Time
+	last  time.Time
+}
+
+func newMetricStamper(clock Clock) *metricStamper {
+	now := clock.Now()
+	return &metricStamper{clock: clock, start: now, last: now}
+}
+
+func (s *metricStamper) stamp(rm *metricdata.ResourceMetrics) {
+	s.mu.Lock()
+	now, last := s.clock.Now(), s.last
+	s.last = now
+	s.mu.Unlock()
+
+	start := func(temporality metricdata.Temporality) time.Time {
+		if temporality == metricdata.DeltaTemporality {
+			return last
+		}
+		return s.start
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				stampPoints(data.DataPoints, s.start, now)
+			case metricdata.Gauge[float64]:
+				stampPoints(data.DataPoints, s.start, now)
+			case metricdata.Sum[int64]:
+				stampPoints(data.DataPoints, start(data.Temporality), now)
+			case metricdata.Sum[float64]:
+				stampPoints(data.DataPoints, start(data.Temporality), now)
+			case metricdata.Histogram[int64]:
+				stampHistogramPoints(data.DataPoints, start(data.Temporality), now)
+			case metricdata.Histogram[float64]:
+				stampHistogramPoints(data.DataPoints, start(data.Temporality), now)
+			case metricdata.ExponentialHistogram[int64]:
+				stampExponentialHistogramPoints(data.DataPoints, start(data.Temporality), now)
+			case metricdata.ExponentialHistogram[float64]:
+				stampExponentialHistogramPoints(data.DataPoints, start(data.Temporality), now)
+			}
+		}
+	}
+}
+
+// stampPoints sets the times of points in place. Points without a start
+// time, such as those of gauges, keep none.
+func stampPoints[N int64 | float64](points []metricdata.DataPoint[N], start, now time.Time) {
+	for i := range points {
+		if !points[i].StartTime.IsZero() {
+			points[i].StartTime = start
+		}
+		points[i].Time = now
+	}
+}
+
+func stampHistogramPoints[N int64 | float64](points []metricdata.HistogramDataPoint[N], start, now time.Time) {
+	for i := range points {
+		points[i].StartTime = start
+		points[i].Time = now
+	}
+}
+
+func stampExponentialHistogramPoints[N int64 | float64](points []metricdata.ExponentialHistogramDataPoint[N], start, now time.Time) {
+	for i := range points {
+		points[i].StartTime = start
+		points[i].Time = now
+	}
+}
+
+// stampedExporter stamps the metrics with a Clock before exporting them.
+type stampedExporter struct {
+	sdkmetric.Exporter
+	stamper *metricStamper
+}
+
+func (e stampedExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.stamper.stamp(rm)
+	return e.Exporter.Export(ctx, rm)
+}
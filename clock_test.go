@@ -0,0 +1,122 @@
+package otelemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Isolated:      true,
+		Clock:         clock,
+		TracerOptions: TracerOptions{Exporter: ExporterMemory},
+		MetricOptions: MetricOptions{Exporter: ExporterMemory},
+		LoggerOptions: LoggerOptions{Exporter: ExporterMemory},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+	clock.Advance(time.Second)
+	span.AddEvent("event")
+	tel.Log().Info(ctx, "message")
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	clock.Advance(time.Second)
+	span.End()
+
+	spans := tel.Memory().Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, start, spans[0].StartTime)
+	assert.Equal(t, start.Add(time.Second), spans[0].Events[0].Time)
+	assert.Equal(t, start.Add(2*time.Second), spans[0].EndTime)
+
+	logs := tel.Memory().Logs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, start.Add(time.Second), logs[0].Timestamp())
+	assert.Equal(t, start.Add(time.Second), logs[0].ObservedTimestamp())
+
+	rm, err := tel.Memory().Metrics(ctx)
+	require.NoError(t, err)
+	point := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints[0]
+	assert.Equal(t, start, point.StartTime, "cumulative points start with the provider")
+	assert.Equal(t, start.Add(2*time.Second), point.Time)
+}
+
+func TestMetricStamperDelta(t *testing.T) {
+	start := time.Unix(100, 0)
+	clock := &fakeClock{now: start}
+	stamper := newMetricStamper(clock)
+
+	collect := func() metricdata.DataPoint[int64] {
+		rm := metricdata.ResourceMetrics{ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: []metricdata.Metrics{{
+			Data: metricdata.Sum[int64]{
+				Temporality: metricdata.DeltaTemporality,
+				DataPoints:  []metricdata.DataPoint[int64]{{StartTime: time.Now(), Time: time.Now()}},
+			},
+		}}}}}
+		stamper.stamp(&rm)
+		return rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64]).DataPoints[0]
+	}
+
+	clock.Advance(time.Second)
+	first := collect()
+	clock.Advance(time.Second)
+	second := collect()
+
+	assert.Equal(t, start, first.StartTime)
+	assert.Equal(t, start.Add(time.Second), first.Time)
+	assert.Equal(t, first.Time, second.StartTime, "delta points start with the previous collection")
+	assert.Equal(t, start.Add(2*time.Second), second.Time)
+}
+
+func TestDeterministicIDGenerator(t *testing.T) {
+	traceIDs := func(seed int64) []trace.TraceID {
+		tel, err := New(Config{
+			Service:       Service{Name: "test-service"},
+			Isolated:      true,
+			TracerOptions: TracerOptions{Exporter: ExporterMemory, IDGenerator: NewDeterministicIDGenerator(seed)},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+		var ids []trace.TraceID
+		for i := 0; i < 3; i++ {
+			_, span := tel.Trace().StartSpan(context.Background(), "span")
+			ids = append(ids, span.Span().SpanContext().TraceID())
+			span.End()
+		}
+		return ids
+	}
+
+	first := traceIDs(1)
+	assert.Equal(t, first, traceIDs(1))
+	assert.NotEqual(t, first, traceIDs(2))
+	assert.NotEqual(t, first[0], first[1])
+}
+
+func TestXRayIDGenerator(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	generator := NewXRayIDGenerator(clock)
+
+	traceID, spanID := generator.NewIDs(context.Background())
+	assert.Equal(t, uint32(1700000000), binary.BigEndian.Uint32(traceID[:4]))
+	assert.True(t, traceID.IsValid())
+	assert.True(t, spanID.IsValid())
+
+	other, _ := generator.NewIDs(context.Background())
+	assert.NotEqual(t, traceID, other)
+	assert.Equal(t, traceID[:4], other[:4])
+}
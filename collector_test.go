@@ -0,0 +1,135 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectorMerge(t *testing.T) {
+	shared := Collector{
+		Host:         "collector",
+		Port:         "4317",
+		Protocol:     ProtocolGRPC,
+		Headers:      map[string]string{"tenant": "a", "team": "core"},
+		ExportPolicy: ExportPolicy{Timeout: time.Second},
+	}
+
+	assert.Equal(t, shared, shared.merge(nil))
+
+	merged := shared.merge(&Collector{
+		Host:         "metrics-gateway",
+		Protocol:     ProtocolHTTPProtobuf,
+		Headers:      map[string]string{"tenant": "b"},
+		ExportPolicy: ExportPolicy{Compression: CompressionGzip},
+	})
+
+	assert.Equal(t, Collector{
+		Host:         "metrics-gateway",
+		Port:         "4317",
+		Protocol:     ProtocolHTTPProtobuf,
+		Headers:      map[string]string{"tenant": "b", "team": "core"},
+		ExportPolicy: ExportPolicy{Timeout: time.Second, Compression: CompressionGzip},
+	}, merged)
+	assert.Equal(t, "a", shared.Headers["tenant"], "shared headers must not be modified")
+}
+
+func TestNewWithPerSignalCollector(t *testing.T) {
+	shared, metrics := newHTTPReceiver(t), newHTTPReceiver(t)
+
+	sharedCollector := shared.collector(t)
+	sharedCollector.Headers = map[string]string{"tenant": "acme"}
+
+	metricsCollector := metrics.collector(t)
+	metricsCollector.Headers = map[string]string{"api-key": "secret"}
+	metricsCollector.Compression = CompressionGzip
+
+	tel, err := New(Config{
+		Service:     Service{Name: "test-service"},
+		Collector:   sharedCollector,
+		WithTraces:  true,
+		WithMetrics: true,
+		TracerOptions: TracerOptions{
+			Collector: &Collector{Headers: map[string]string{"signal": "traces"}},
+		},
+		MetricOptions: MetricOptions{Collector: &metricsCollector},
+	})
+	require.NoError(t, err)
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+	span.End()
+
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	require.NoError(t, tel.Shutdown(context.Background()))
+
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	assert.Equal(t, []string{"/v1/traces"}, shared.paths)
+	assert.Equal(t, "acme", shared.headers["/v1/traces"].Get("tenant"))
+	assert.Equal(t, "traces", shared.headers["/v1/traces"].Get("signal"))
+
+	assert.Equal(t, []string{"/v1/metrics"}, metrics.paths)
+	assert.Equal(t, "secret", metrics.headers["/v1/metrics"].Get("api-key"))
+	assert.Equal(t, "acme", metrics.headers["/v1/metrics"].Get("tenant"))
+	assert.Equal(t, "gzip", metrics.headers["/v1/metrics"].Get("Content-Encoding"))
+}
+
+func TestValidatePerSignalCollector(t *testing.T) {
+	err := Config{
+		Service:     Service{Name: "test-service"},
+		WithTraces:  true,
+		WithMetrics: true,
+		WithLogs:    true,
+		TracerOptions: TracerOptions{
+			Collector: &Collector{Host: "localhost", Port: "4317"},
+		},
+		LoggerOptions: LoggerOptions{
+			Collector: &Collector{Host: "localhost", Port: "4317", ExportPolicy: ExportPolicy{Compression: "zstd"}},
+		},
+	}.Validate()
+
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "collector host is required")
+	assert.ErrorContains(t, err, `logs: unsupported collector compression "zstd"`)
+	assert.NotContains(t, err.Error(), "traces:")
+}
+
+func TestConfigFromEnvPerSignal(t *testing.T) {
+	t.Setenv(EnvExporterEndpoint, "http://collector:4317")
+	t.Setenv(EnvExporterHeaders, "tenant=acme")
+	t.Setenv(EnvExporterTimeout, "2000")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "https://metrics-gateway/otlp/metrics")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_HEADERS", "api-key=secret%20key")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_COMPRESSION", "gzip")
+
+	cfg := ConfigFromEnv()
+
+	assert.Equal(t, Collector{
+		Host:         "collector",
+		Port:         "4317",
+		Headers:      map[string]string{"tenant": "acme"},
+		ExportPolicy: ExportPolicy{Timeout: 2 * time.Second},
+	}, cfg.Collector)
+	assert.Nil(t, cfg.TracerOptions.Collector)
+	assert.Nil(t, cfg.LoggerOptions.Collector)
+
+	assert.Equal(t, &Collector{
+		Host:         "metrics-gateway",
+		Port:         defaultCollectorHTTPPort,
+		Protocol:     ProtocolHTTPProtobuf,
+		TLS:          &TLS{},
+		Headers:      map[string]string{"api-key": "secret key"},
+		ExportPolicy: ExportPolicy{Compression: CompressionGzip},
+	}, cfg.MetricOptions.Collector)
+	assert.Equal(t, "/otlp/metrics", cfg.MetricOptions.URLPath)
+}
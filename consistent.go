@@ -0,0 +1,53 @@
+package otelemetry
+
+import (
+	"fmt"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// consistentSampler implements OpenTelemetry consistent probability
+// sampling: a span is sampled when the randomness value of its trace is at
+// least the threshold of the probability. Every service of a trace reads the
+// same randomness value, so a service sampling at a higher probability keeps
+// a superset of the traces kept by one sampling at a lower probability.
+//
+// The randomness is the rv value of the ot tracestate entry, or the 56 least
+// significant bits of the trace ID when there is none. Roots record it as rv
+// so that services which do not assume random trace IDs decide alike. The
+// threshold of a sampled span is recorded as th, and removed when the span
+// is dropped.
+type consistentSampler struct {
+	probability float64
+	threshold   uint64
+	th          string
+}
+
+func newConsistentSampler(probability float64) *consistentSampler {
+	return &consistentSampler{
+		probability: probability,
+		threshold:   threshold(probability),
+		th:          encodeThreshold(probability),
+	}
+}
+
+func (s *consistentSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	ts := parent.TraceState()
+
+	r, recorded := randomness(ts, p.TraceID)
+	if !recorded && !parent.IsValid() {
+		ts = withOTValue(ts, "rv", encodeRandomness(r))
+	}
+
+	if s.threshold >= maxThreshold || r < s.threshold {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: withoutOTValue(ts, "th")}
+	}
+
+	return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: withOTValue(ts, "th", s.th)}
+}
+
+func (s *consistentSampler) Description() string {
+	return fmt.Sprintf("ConsistentProbabilityBased{%g}", s.probability)
+}
@@ -0,0 +1,146 @@
+package otelemetry
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConsistentSamplerDecisions(t *testing.T) {
+	var (
+		half    = newConsistentSampler(0.5)
+		quarter = newConsistentSampler(0.25)
+		rnd     = rand.New(rand.NewSource(1))
+
+		sampledHalf, sampledQuarter int
+	)
+
+	for i := 0; i < 2000; i++ {
+		params := sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: randomTraceID(rnd)}
+		h, q := half.ShouldSample(params), quarter.ShouldSample(params)
+
+		r, _ := randomness(trace.TraceState{}, params.TraceID)
+		rv, ok := otValue(h.Tracestate, "rv")
+		require.True(t, ok, "roots record their randomness")
+		assert.Equal(t, encodeRandomness(r), rv)
+
+		if q.Decision == sdktrace.RecordAndSample {
+			sampledQuarter++
+			assert.Equal(t, sdktrace.RecordAndSample, h.Decision, "a lower probability samples a subset")
+			th, _ := otValue(q.Tracestate, "th")
+			assert.Equal(t, "c", th)
+		}
+
+		if h.Decision == sdktrace.RecordAndSample {
+			sampledHalf++
+			th, _ := otValue(h.Tracestate, "th")
+			assert.Equal(t, "8", th)
+		} else {
+			_, ok := otValue(h.Tracestate, "th")
+			assert.False(t, ok, "dropped spans carry no threshold")
+		}
+	}
+
+	assert.InDelta(t, 1000, sampledHalf, 100)
+	assert.InDelta(t, 500, sampledQuarter, 100)
+}
+
+func TestConsistentSamplerUsesRecordedRandomness(t *testing.T) {
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	child := func(ot string) sdktrace.SamplingParameters {
+		ts, err := trace.ParseTraceState("ot=" + ot + ",vendor=value")
+		require.NoError(t, err)
+
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{1},
+			TraceState: ts,
+			Remote:     true,
+		}))
+		return sdktrace.SamplingParameters{ParentContext: ctx, TraceID: traceID}
+	}
+
+	result := newConsistentSampler(0.01).ShouldSample(child("rv:fffffffffffffe"))
+	assert.Equal(t, sdktrace.RecordAndSample, result.Decision)
+	assert.Equal(t, "th:fd70a3d70a3d71;rv:fffffffffffffe", result.Tracestate.Get("ot"))
+	assert.Equal(t, "value", result.Tracestate.Get("vendor"))
+
+	// the trace ID alone would be sampled
+	result = newConsistentSampler(0.99).ShouldSample(child("th:0;rv:00000000000001"))
+	assert.Equal(t, sdktrace.Drop, result.Decision)
+	assert.Equal(t, "rv:00000000000001", result.Tracestate.Get("ot"))
+
+	// an invalid rv falls back to the trace ID
+	result = newConsistentSampler(0.99).ShouldSample(child("rv:xyz"))
+	assert.Equal(t, sdktrace.RecordAndSample, result.Decision)
+
+	result = newConsistentSampler(0).ShouldSample(child("rv:ffffffffffffff"))
+	assert.Equal(t, sdktrace.Drop, result.Decision)
+}
+
+func TestConsistentSamplingAcrossServices(t *testing.T) {
+	newService := func(ratio float64) Telemetry {
+		tel, err := New(Config{
+			Service:       Service{Name: "test-service"},
+			TracerOptions: TracerOptions{Sampler: &Sampler{Type: SamplerConsistentProbability, Ratio: ratio}},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+		return tel
+	}
+	frontend, backend, audit := newService(0.5), newService(0.5), newService(0.25)
+
+	var sampled, partial int
+	for i := 0; i < 200; i++ {
+		ctx, root := frontend.Trace().StartSpan(context.Background(), "request")
+		carrier := make(map[string]string)
+		Inject(ctx, carrier)
+
+		assert.Contains(t, carrier["tracestate"], "rv:")
+
+		_, same := backend.Trace().StartSpan(Extract(context.Background(), carrier), "handle")
+		_, lower := audit.Trace().StartSpan(Extract(context.Background(), carrier), "audit")
+
+		assert.Equal(t, root.Span().IsRecording(), same.Span().IsRecording(), "equal ratios take the same decision")
+		assert.Equal(t, root.Span().SpanContext().TraceState().Get("ot"), same.Span().SpanContext().TraceState().Get("ot"))
+		if lower.Span().IsRecording() {
+			assert.True(t, root.Span().IsRecording(), "a lower ratio samples a subset")
+			partial++
+		}
+		if root.Span().IsRecording() {
+			sampled++
+		}
+
+		lower.End()
+		same.End()
+		root.End()
+	}
+
+	assert.InDelta(t, 100, sampled, 30)
+	assert.InDelta(t, 50, partial, 25)
+}
+
+func TestConsistentSamplerValidation(t *testing.T) {
+	cfg := Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{
+			Type: SamplerParentBased,
+			Root: &Sampler{Type: SamplerConsistentProbability, Ratio: 1.5},
+		}},
+		Propagators: []string{"baggage"},
+	}
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "sampler root: ratio 1.5 out of range [0, 1]")
+	assert.ErrorContains(t, err, `consistent probability sampling requires the "tracecontext" propagator`)
+
+	cfg.TracerOptions.Sampler.Root.Ratio = 0.5
+	cfg.Propagators = nil
+	assert.NoError(t, cfg.Validate())
+}
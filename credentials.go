@@ -0,0 +1,132 @@
+package otelemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Credentials are the headers added to every export request, typically an
+// Authorization header carrying a bearer token.
+type Credentials struct {
+	Headers map[string]string
+	// ExpiresAt is the expiry of the headers. Zero means they never expire.
+	ExpiresAt time.Time
+}
+
+// CredentialsProvider supplies the credentials of the export requests.
+//
+// Credentials are cached and the provider is called again once 80% of their
+// lifetime has elapsed, so tokens are refreshed before they expire. If a
+// refresh fails, the cached credentials are used until they expire.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsFunc adapts an ordinary function to a CredentialsProvider.
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials implements CredentialsProvider.
+func (f CredentialsFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// credentialsCache caches the credentials of a provider and refreshes them
+// before they expire. It implements credentials.PerRPCCredentials for gRPC.
+type credentialsCache struct {
+	provider CredentialsProvider
+	now      func() time.Time
+
+	mu        sync.Mutex
+	creds     Credentials
+	fetched   bool
+	refreshAt time.Time
+}
+
+// cachedCredentials wraps p in a credentialsCache unless it already is one.
+func cachedCredentials(p CredentialsProvider) CredentialsProvider {
+	switch p.(type) {
+	case nil, *credentialsCache:
+		return p
+	}
+
+	return &credentialsCache{provider: p, now: time.Now}
+}
+
+// Credentials implements CredentialsProvider.
+func (c *credentialsCache) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.fetched && (c.creds.ExpiresAt.IsZero() || now.Before(c.refreshAt)) {
+		return c.creds, nil
+	}
+
+	creds, err := c.provider.Credentials(ctx)
+	if err != nil {
+		if c.fetched && now.Before(c.creds.ExpiresAt) {
+			otel.Handle(fmt.Errorf("otelemetry: refreshing credentials: %w", err))
+			return c.creds, nil
+		}
+		return Credentials{}, fmt.Errorf("otelemetry: fetching credentials: %w", err)
+	}
+
+	c.creds, c.fetched = creds, true
+	if !creds.ExpiresAt.IsZero() {
+		c.refreshAt = now.Add(creds.ExpiresAt.Sub(now) * 4 / 5)
+	}
+
+	return creds, nil
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (c *credentialsCache) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	creds, err := c.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	md := make(map[string]string, len(creds.Headers))
+	for k, v := range creds.Headers {
+		md[strings.ToLower(k)] = v
+	}
+
+	return md, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+// Plaintext is allowed, as for the rest of the collector settings.
+func (c *credentialsCache) RequireTransportSecurity() bool {
+	return false
+}
+
+// credentialsTransport adds the credentials headers to every HTTP request.
+type credentialsTransport struct {
+	base  http.RoundTripper
+	creds CredentialsProvider
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, err := t.creds.Credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	for k, v := range creds.Headers {
+		req.Header.Set(k, v)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// newCredentialsPerRPC returns the gRPC per-RPC credentials of p.
+func newCredentialsPerRPC(p CredentialsProvider) *credentialsCache {
+	return cachedCredentials(p).(*credentialsCache)
+}
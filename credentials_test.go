@@ -0,0 +1,233 @@
+package otelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenIssuer is a stand-in identity provider issuing short-lived bearer
+// tokens, and the authorization check of a collector rejecting expired ones.
+type tokenIssuer struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	expiry   map[string]time.Time
+	accepted int
+	rejected int
+}
+
+func newTokenIssuer(ttl time.Duration) *tokenIssuer {
+	return &tokenIssuer{ttl: ttl, expiry: make(map[string]time.Time)}
+}
+
+func (i *tokenIssuer) Credentials(context.Context) (Credentials, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	token := fmt.Sprintf("token-%d", len(i.expiry))
+	expiresAt := time.Now().Add(i.ttl)
+	i.expiry[token] = expiresAt
+
+	return Credentials{Headers: map[string]string{"Authorization": "Bearer " + token}, ExpiresAt: expiresAt}, nil
+}
+
+// authorize reports whether the Authorization value carries an unexpired token.
+func (i *tokenIssuer) authorize(authorization string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	expiresAt, ok := i.expiry[strings.TrimPrefix(authorization, "Bearer ")]
+	if ok && time.Now().Before(expiresAt) {
+		i.accepted++
+		return true
+	}
+	i.rejected++
+	return false
+}
+
+func (i *tokenIssuer) counts() (issued, accepted, rejected int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return len(i.expiry), i.accepted, i.rejected
+}
+
+type traceService struct {
+	collectortrace.UnimplementedTraceServiceServer
+}
+
+func (traceService) Export(context.Context, *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func newAuthHTTPCollector(t *testing.T, issuer *tokenIssuer) Collector {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !issuer.authorize(r.Header.Get("Authorization")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	return Collector{Host: host, Port: port, Protocol: ProtocolHTTPProtobuf}
+}
+
+func newAuthGRPCCollector(t *testing.T, issuer *tokenIssuer) Collector {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("authorization"); len(values) == 0 || !issuer.authorize(values[0]) {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}))
+	collectortrace.RegisterTraceServiceServer(server, traceService{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	host, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+	return Collector{Host: host, Port: port, Protocol: ProtocolGRPC}
+}
+
+func TestCredentialsProviderRefreshesTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector func(*testing.T, *tokenIssuer) Collector
+	}{
+		{"http", newAuthHTTPCollector},
+		{"grpc", newAuthGRPCCollector},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newTokenIssuer(300 * time.Millisecond)
+
+			collector := tt.collector(t, issuer)
+			collector.Credentials = issuer
+
+			tel, err := New(Config{
+				Service:    Service{Name: "test-service"},
+				Collector:  collector,
+				WithTraces: true,
+			})
+			require.NoError(t, err)
+			provider := tel.(*telemetry).tracerProvider
+
+			for i := 0; i < 3; i++ {
+				_, span := tel.Trace().StartSpan(context.Background(), "span")
+				span.End()
+				require.NoError(t, provider.ForceFlush(context.Background()))
+				time.Sleep(200 * time.Millisecond)
+			}
+			require.NoError(t, tel.Shutdown(context.Background()))
+
+			issued, accepted, rejected := issuer.counts()
+			assert.Equal(t, 3, accepted)
+			assert.Zero(t, rejected)
+			assert.GreaterOrEqual(t, issued, 2, "tokens must be refreshed before they expire")
+		})
+	}
+}
+
+func TestCredentialsHTTPClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	tel, err := New(Config{
+		Service: Service{Name: "test-service"},
+		Collector: Collector{
+			Host:         host,
+			Port:         port,
+			Protocol:     ProtocolHTTPProtobuf,
+			Credentials:  newTokenIssuer(time.Minute),
+			ExportPolicy: ExportPolicy{Timeout: 100 * time.Millisecond, Retry: &RetryPolicy{Disabled: true}},
+		},
+		WithTraces: true,
+	})
+	require.NoError(t, err)
+	provider := tel.(*telemetry).tracerProvider
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	span.End()
+
+	start := time.Now()
+	assert.Error(t, provider.ForceFlush(context.Background()))
+	assert.Less(t, time.Since(start), 2*time.Second, "the export must give up after the policy timeout")
+	_ = tel.Shutdown(context.Background())
+}
+
+func TestCredentialsCache(t *testing.T) {
+	var (
+		now   = time.Unix(0, 0)
+		calls int
+		fail  bool
+	)
+	cache := &credentialsCache{
+		now: func() time.Time { return now },
+		provider: CredentialsFunc(func(context.Context) (Credentials, error) {
+			if fail {
+				return Credentials{}, errors.New("identity provider down")
+			}
+			calls++
+			return Credentials{
+				Headers:   map[string]string{"Authorization": fmt.Sprintf("Bearer %d", calls)},
+				ExpiresAt: now.Add(10 * time.Second),
+			}, nil
+		}),
+	}
+
+	md, err := cache.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer 1"}, md)
+
+	// cached until 80% of the lifetime elapsed
+	now = now.Add(7 * time.Second)
+	md, _ = cache.GetRequestMetadata(context.Background())
+	assert.Equal(t, "Bearer 1", md["authorization"])
+
+	now = now.Add(time.Second)
+	md, _ = cache.GetRequestMetadata(context.Background())
+	assert.Equal(t, "Bearer 2", md["authorization"])
+
+	// a failed refresh keeps the unexpired token, then reports the error
+	fail = true
+	now = now.Add(9 * time.Second)
+	md, err = cache.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer 2", md["authorization"])
+
+	now = now.Add(time.Second)
+	_, err = cache.GetRequestMetadata(context.Background())
+	assert.ErrorContains(t, err, "identity provider down")
+}
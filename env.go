@@ -0,0 +1,467 @@
+package otelemetry
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Environment variables defined by the OpenTelemetry specification
+// that are honoured by ConfigFromEnv and NewFromEnv.
+//
+// Every OTEL_EXPORTER_OTLP_* variable also exists per signal, e.g.
+// OTEL_EXPORTER_OTLP_TRACES_HEADERS, and sets the Collector override of the
+// signal's options.
+const (
+	EnvSDKDisabled            = "OTEL_SDK_DISABLED"
+	EnvServiceName            = "OTEL_SERVICE_NAME"
+	EnvResourceAttributes     = "OTEL_RESOURCE_ATTRIBUTES"
+	EnvExporterEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvExporterProtocol       = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	EnvExporterCertificate    = "OTEL_EXPORTER_OTLP_CERTIFICATE"
+	EnvExporterClientCert     = "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"
+	EnvExporterClientKey      = "OTEL_EXPORTER_OTLP_CLIENT_KEY"
+	EnvExporterHeaders        = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvExporterTimeout        = "OTEL_EXPORTER_OTLP_TIMEOUT"
+	EnvExporterCompression    = "OTEL_EXPORTER_OTLP_COMPRESSION"
+	EnvExporterTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvExporterMetricEndpoint = "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"
+	EnvExporterLogsEndpoint   = "OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"
+	EnvTracesExporter         = "OTEL_TRACES_EXPORTER"
+	EnvMetricsExporter        = "OTEL_METRICS_EXPORTER"
+	EnvLogsExporter           = "OTEL_LOGS_EXPORTER"
+	EnvTracesSampler          = "OTEL_TRACES_SAMPLER"
+	EnvTracesSamplerArg       = "OTEL_TRACES_SAMPLER_ARG"
+	EnvMetricExportInterval   = "OTEL_METRIC_EXPORT_INTERVAL"
+	EnvPropagators            = "OTEL_PROPAGATORS"
+)
+
+// Ports used when an endpoint omits it.
+const (
+	defaultCollectorPort     = "4317"
+	defaultCollectorHTTPPort = "4318"
+)
+
+// ConfigFromEnv builds a Config from the standard OTEL_* environment variables.
+//
+// Invalid values are reported through otel.Handle and otherwise ignored,
+// as required by the specification.
+func ConfigFromEnv() Config {
+	return mergeEnv(Config{})
+}
+
+// NewFromEnv creates a new Telemetry instance from cfg merged with the
+// standard OTEL_* environment variables.
+//
+// Values set explicitly on cfg take precedence: the environment only fills in
+// fields that are left at their zero value. Option slices are merged so that
+// options derived from the environment come first and options from cfg are
+// applied after them, overriding them where they overlap.
+func NewFromEnv(cfg Config) (Telemetry, error) {
+	return New(mergeEnv(cfg))
+}
+
+func mergeEnv(cfg Config) Config {
+	if v, ok := lookupEnv(EnvSDKDisabled); ok && !cfg.Disabled {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			otel.Handle(fmt.Errorf("%s: %w", EnvSDKDisabled, err))
+		}
+		cfg.Disabled = disabled
+	}
+
+	// service identity and resource
+	attrs := resourceAttributesFromEnv()
+	if v, ok := lookupEnv(EnvServiceName); ok {
+		attrs[string(semconv.ServiceNameKey)] = v
+	}
+
+	fillString(&cfg.Service.Name, attrs, string(semconv.ServiceNameKey))
+	fillString(&cfg.Service.Namespace, attrs, string(semconv.ServiceNamespaceKey))
+	fillString(&cfg.Service.Version, attrs, string(semconv.ServiceVersionKey))
+
+	if len(attrs) > 0 {
+		kv := make([]attribute.KeyValue, 0, len(attrs))
+		for k, v := range attrs {
+			kv = append(kv, attribute.String(k, v))
+		}
+		cfg.ResourceOptions = append([]sdkresource.Option{sdkresource.WithAttributes(kv...)}, cfg.ResourceOptions...)
+	}
+
+	// collector, then the per-signal overrides
+	basePath, _ := collectorFromEnv(&cfg.Collector, "", ProtocolGRPC)
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	signals := []struct {
+		name     string
+		suffix   string
+		override **Collector
+		urlPath  *string
+	}{
+		{"TRACES", "/v1/traces", &cfg.TracerOptions.Collector, &cfg.TracerOptions.URLPath},
+		{"METRICS", "/v1/metrics", &cfg.MetricOptions.Collector, &cfg.MetricOptions.URLPath},
+		{"LOGS", "/v1/logs", &cfg.LoggerOptions.Collector, &cfg.LoggerOptions.URLPath},
+	}
+	for _, signal := range signals {
+		var override Collector
+		if *signal.override != nil {
+			override = **signal.override
+		}
+
+		// a per-signal endpoint is used as is, including its URL path
+		path, ok := collectorFromEnv(&override, signal.name, cfg.Collector.Protocol)
+		if ok {
+			*signal.override = &override
+			if *signal.urlPath == "" {
+				*signal.urlPath = path
+			}
+		}
+
+		// the shared endpoint is a base URL the OTLP/HTTP signal paths are
+		// appended to
+		protocol := cfg.Collector.Protocol
+		if override.Protocol != "" {
+			protocol = override.Protocol
+		}
+		if basePath != "" && *signal.urlPath == "" && override.Host == "" && override.Port == "" && protocol.isHTTP() {
+			*signal.urlPath = basePath + signal.suffix
+		}
+	}
+
+	// exporters
+	fillExporter(&cfg.WithTraces, &cfg.TracerOptions.Exporter, EnvTracesExporter)
+	fillExporter(&cfg.WithMetrics, &cfg.MetricOptions.Exporter, EnvMetricsExporter)
+	fillExporter(&cfg.WithLogs, &cfg.LoggerOptions.Exporter, EnvLogsExporter)
+
+	// the OTLP signals default to the local collector of their protocol
+	shared := cfg.Collector.Host != "" || cfg.Collector.Port != ""
+	traces, metrics, logs := cfg.exporters()
+	otlp := []struct {
+		exporter Exporter
+		override *Collector
+	}{
+		{traces, cfg.TracerOptions.Collector},
+		{metrics, cfg.MetricOptions.Collector},
+		{logs, cfg.LoggerOptions.Collector},
+	}
+	for _, signal := range otlp {
+		if signal.exporter != ExporterOTLP {
+			continue
+		}
+		if o := signal.override; o != nil && o.Protocol != "" && o.Protocol != cfg.Collector.Protocol && !shared {
+			defaultEndpoint(o, o.Protocol)
+			continue
+		}
+		defaultEndpoint(&cfg.Collector, cfg.Collector.Protocol)
+	}
+
+	// sampler
+	if v, ok := lookupEnv(EnvTracesSampler); ok {
+		arg, _ := lookupEnv(EnvTracesSamplerArg)
+		if sampler, err := samplerFromEnv(v, arg); err != nil {
+			otel.Handle(fmt.Errorf("%s: %w", EnvTracesSampler, err))
+		} else if cfg.TracerOptions.Sampler == nil {
+			cfg.TracerOptions.Sampler = sampler
+		}
+	}
+
+	// propagators, unknown names are skipped
+	if v, ok := lookupEnv(EnvPropagators); ok && len(cfg.Propagators) == 0 {
+		cfg.Propagators = propagatorsFromEnv(v)
+	}
+
+	// metric export interval, in milliseconds
+	if v, ok := lookupEnv(EnvMetricExportInterval); ok && cfg.MetricOptions.PeriodicInterval == 0 {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			otel.Handle(fmt.Errorf("%s: invalid interval %q", EnvMetricExportInterval, v))
+		} else {
+			cfg.MetricOptions.PeriodicInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	return cfg
+}
+
+// propagatorsFromEnv parses a comma-separated list of propagator names,
+// dropping duplicates and reporting unsupported names.
+func propagatorsFromEnv(v string) []string {
+	var names []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || slices.Contains(names, name) {
+			continue
+		}
+		if _, ok := propagators[name]; !ok {
+			otel.Handle(fmt.Errorf("%s: unsupported propagator %q", EnvPropagators, name))
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// lookupEnv returns the trimmed value of the environment variable key,
+// treating empty values as unset.
+func lookupEnv(key string) (string, bool) {
+	v := strings.TrimSpace(os.Getenv(key))
+	return v, v != ""
+}
+
+// resourceAttributesFromEnv parses OTEL_RESOURCE_ATTRIBUTES.
+func resourceAttributesFromEnv() map[string]string {
+	v, ok := lookupEnv(EnvResourceAttributes)
+	if !ok {
+		return make(map[string]string)
+	}
+
+	attrs, err := parseResourceAttributes(v)
+	if err != nil {
+		otel.Handle(fmt.Errorf("%s: %w", EnvResourceAttributes, err))
+	}
+
+	return attrs
+}
+
+// parseResourceAttributes parses a key1=value1,key2=value2 list with
+// percent-encoded values. Invalid pairs are skipped and reported in the error.
+func parseResourceAttributes(v string) (map[string]string, error) {
+	var (
+		attrs = make(map[string]string)
+		errs  []error
+	)
+
+	for _, pair := range strings.Split(v, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			errs = append(errs, fmt.Errorf("invalid attribute %q", pair))
+			continue
+		}
+
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for %q: %w", key, err))
+			continue
+		}
+		attrs[key] = decoded
+	}
+
+	return attrs, errors.Join(errs...)
+}
+
+// fillString sets *dst from attrs[key] when *dst is empty and removes the key
+// from attrs, so the explicit Service values are never overridden by a
+// generic resource attribute.
+func fillString(dst *string, attrs map[string]string, key string) {
+	v, ok := attrs[key]
+	if !ok {
+		return
+	}
+	delete(attrs, key)
+
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+// fillExporter enables the OTLP pipeline of a signal when the corresponding
+// OTEL_*_EXPORTER variable is "otlp" and disables the signal when it is
+// "none". "console" keeps the stdout exporter.
+func fillExporter(enabled *bool, exporter *Exporter, key string) {
+	v, ok := lookupEnv(key)
+	if !ok || *enabled || *exporter != "" {
+		return
+	}
+
+	switch strings.ToLower(v) {
+	case "otlp":
+		*enabled = true
+	case "console":
+	case "none":
+		*exporter = ExporterNone
+	default:
+		otel.Handle(fmt.Errorf("%s: unsupported exporter %q", key, v))
+	}
+}
+
+// collectorFromEnv fills the zero fields of c from the OTEL_EXPORTER_OTLP_*
+// variables of signal, or from the shared ones when signal is empty.
+// protocol picks the default port when neither c nor the environment set one.
+// It returns the URL path of the endpoint and whether any variable was set.
+func collectorFromEnv(c *Collector, signal string, protocol Protocol) (path string, ok bool) {
+	key := func(name string) string {
+		if signal == "" {
+			return "OTEL_EXPORTER_OTLP_" + name
+		}
+		return "OTEL_EXPORTER_OTLP_" + signal + "_" + name
+	}
+
+	if v, set := lookupEnv(key("PROTOCOL")); set {
+		ok = true
+		if c.Protocol == "" {
+			c.Protocol = Protocol(strings.ToLower(v))
+		}
+	}
+	if c.Protocol != "" {
+		protocol = c.Protocol
+	}
+
+	if v, set := lookupEnv(key("ENDPOINT")); set {
+		ok = true
+		if host, port, p, err := parseEndpoint(v, protocol); err != nil {
+			otel.Handle(fmt.Errorf("%s: %w", key("ENDPOINT"), err))
+		} else if c.Host == "" && c.Port == "" {
+			c.Host, c.Port, path = host, port, p
+			if strings.HasPrefix(strings.ToLower(v), "https://") && c.TLS == nil {
+				c.TLS = &TLS{}
+			}
+		}
+	}
+
+	ca, _ := lookupEnv(key("CERTIFICATE"))
+	cert, _ := lookupEnv(key("CLIENT_CERTIFICATE"))
+	clientKey, _ := lookupEnv(key("CLIENT_KEY"))
+	if ca != "" || cert != "" || clientKey != "" {
+		ok = true
+		if c.TLS == nil {
+			c.TLS = &TLS{CAFile: ca, CertFile: cert, KeyFile: clientKey}
+		}
+	}
+
+	if v, set := lookupEnv(key("HEADERS")); set {
+		ok = true
+		headers, err := parseResourceAttributes(v)
+		if err != nil {
+			otel.Handle(fmt.Errorf("%s: %w", key("HEADERS"), err))
+		}
+		if len(c.Headers) == 0 && len(headers) > 0 {
+			c.Headers = headers
+		}
+	}
+
+	if v, set := lookupEnv(key("TIMEOUT")); set {
+		ok = true
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			otel.Handle(fmt.Errorf("%s: invalid timeout %q", key("TIMEOUT"), v))
+		} else if c.Timeout == 0 {
+			c.Timeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if v, set := lookupEnv(key("COMPRESSION")); set {
+		ok = true
+		if c.Compression == "" {
+			c.Compression = Compression(strings.ToLower(v))
+		}
+	}
+
+	return path, ok
+}
+
+// defaultEndpoint fills the host and port of c left empty with the default
+// endpoint of protocol: localhost:4317 for gRPC, localhost:4318 for
+// OTLP/HTTP.
+func defaultEndpoint(c *Collector, protocol Protocol) {
+	if c.Host == "" {
+		c.Host = "localhost"
+	}
+	if c.Port == "" {
+		c.Port = defaultCollectorPort
+		if protocol.isHTTP() {
+			c.Port = defaultCollectorHTTPPort
+		}
+	}
+}
+
+// parseEndpoint splits an OTLP endpoint, with or without a URL scheme,
+// into host, port and URL path. The protocol's default port is used
+// when the endpoint omits it.
+func parseEndpoint(endpoint string, protocol Protocol) (host, port, path string, err error) {
+	defaultPort := defaultCollectorPort
+	if protocol.isHTTP() {
+		defaultPort = defaultCollectorHTTPPort
+	}
+
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", "", "", err
+		}
+		if u.Hostname() == "" {
+			return "", "", "", fmt.Errorf("missing host in endpoint %q", endpoint)
+		}
+
+		port := u.Port()
+		if port == "" {
+			port = defaultPort
+		}
+		if u.Path == "/" {
+			u.Path = ""
+		}
+		return u.Hostname(), port, u.Path, nil
+	}
+
+	host, port, err = net.SplitHostPort(endpoint)
+	if err != nil {
+		// no port
+		return endpoint, defaultPort, "", nil
+	}
+
+	return host, port, "", nil
+}
+
+// samplerFromEnv maps OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG to a sampler.
+func samplerFromEnv(name, arg string) (*Sampler, error) {
+	ratio := func() (float64, error) {
+		if arg == "" {
+			return 1, nil
+		}
+		r, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", EnvTracesSamplerArg, err)
+		}
+		if r < 0 || r > 1 {
+			return 0, fmt.Errorf("%s: ratio %v out of range [0, 1]", EnvTracesSamplerArg, r)
+		}
+		return r, nil
+	}
+
+	switch strings.ToLower(name) {
+	case "always_on":
+		return &Sampler{Type: SamplerAlwaysOn}, nil
+	case "always_off":
+		return &Sampler{Type: SamplerAlwaysOff}, nil
+	case "traceidratio":
+		r, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return &Sampler{Type: SamplerTraceIDRatio, Ratio: r}, nil
+	case "parentbased_always_on":
+		return &Sampler{Type: SamplerParentBased, Root: &Sampler{Type: SamplerAlwaysOn}}, nil
+	case "parentbased_always_off":
+		return &Sampler{Type: SamplerParentBased, Root: &Sampler{Type: SamplerAlwaysOff}}, nil
+	case "parentbased_traceidratio":
+		r, err := ratio()
+		if err != nil {
+			return nil, err
+		}
+		return &Sampler{Type: SamplerParentBased, Root: &Sampler{Type: SamplerTraceIDRatio, Ratio: r}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler %q", name)
+	}
+}
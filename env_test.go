@@ -0,0 +1,188 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvServiceName, "env-service")
+	t.Setenv(EnvResourceAttributes, "service.namespace=env-ns,deployment.environment=staging%20eu")
+	t.Setenv(EnvExporterEndpoint, "http://collector:4318")
+	t.Setenv(EnvTracesExporter, "otlp")
+	t.Setenv(EnvMetricsExporter, "console")
+	t.Setenv(EnvLogsExporter, "none")
+	t.Setenv(EnvTracesSampler, "parentbased_traceidratio")
+	t.Setenv(EnvTracesSamplerArg, "0.25")
+	t.Setenv(EnvMetricExportInterval, "1500")
+	t.Setenv(EnvPropagators, "b3multi, tracecontext,b3multi")
+
+	cfg := ConfigFromEnv()
+
+	assert.Equal(t, "env-service", cfg.Service.Name)
+	assert.Equal(t, "env-ns", cfg.Service.Namespace)
+	assert.Equal(t, "collector", cfg.Collector.Host)
+	assert.Equal(t, "4318", cfg.Collector.Port)
+	assert.True(t, cfg.WithTraces)
+	assert.False(t, cfg.WithMetrics)
+	assert.Equal(t, ExporterNone, cfg.LoggerOptions.Exporter)
+	assert.Equal(t, 1500*time.Millisecond, cfg.MetricOptions.PeriodicInterval)
+	assert.Equal(t, &Sampler{Type: SamplerParentBased, Root: &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.25}}, cfg.TracerOptions.Sampler)
+	assert.Equal(t, []string{"b3multi", "tracecontext"}, cfg.Propagators)
+	assert.Len(t, cfg.ResourceOptions, 1)
+
+	res, err := newResource(context.Background(), cfg)
+	require.NoError(t, err)
+	v, ok := res.Set().Value("deployment.environment")
+	assert.True(t, ok)
+	assert.Equal(t, "staging eu", v.AsString())
+}
+
+func TestConfigFromEnvEndpointPathPrefix(t *testing.T) {
+	t.Setenv(EnvExporterEndpoint, "http://gw:4318/otel/")
+	t.Setenv(EnvExporterProtocol, "http/protobuf")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "http://logs:4318/custom/logs")
+
+	cfg := mergeEnv(Config{MetricOptions: MetricOptions{URLPath: "/explicit"}})
+
+	assert.Equal(t, "gw", cfg.Collector.Host)
+	assert.Equal(t, "/otel/v1/traces", cfg.TracerOptions.URLPath)
+	assert.Equal(t, "/explicit", cfg.MetricOptions.URLPath, "an explicit path wins")
+	assert.Equal(t, "/custom/logs", cfg.LoggerOptions.URLPath, "a per-signal endpoint is used as is")
+
+	// gRPC has no URL paths
+	t.Setenv(EnvExporterProtocol, "grpc")
+	assert.Empty(t, ConfigFromEnv().TracerOptions.URLPath)
+}
+
+func TestConfigFromEnvExplicitValuesTakePrecedence(t *testing.T) {
+	t.Setenv(EnvServiceName, "env-service")
+	t.Setenv(EnvExporterEndpoint, "collector:4318")
+	t.Setenv(EnvMetricExportInterval, "1500")
+
+	cfg := mergeEnv(Config{
+		Service:       Service{Name: "explicit-service"},
+		Collector:     Collector{Host: "localhost", Port: "4317"},
+		MetricOptions: MetricOptions{PeriodicInterval: time.Second},
+	})
+
+	assert.Equal(t, "explicit-service", cfg.Service.Name)
+	assert.Equal(t, "localhost", cfg.Collector.Host)
+	assert.Equal(t, "4317", cfg.Collector.Port)
+	assert.Equal(t, time.Second, cfg.MetricOptions.PeriodicInterval)
+
+	res, err := newResource(context.Background(), cfg)
+	require.NoError(t, err)
+	v, _ := res.Set().Value("service.name")
+	assert.Equal(t, "explicit-service", v.AsString())
+}
+
+func TestConfigFromEnvIgnoresInvalidValues(t *testing.T) {
+	t.Setenv(EnvTracesSampler, "unknown")
+	t.Setenv(EnvMetricExportInterval, "-1")
+	t.Setenv(EnvSDKDisabled, "maybe")
+	t.Setenv(EnvPropagators, "xray,zipkin")
+
+	cfg := ConfigFromEnv()
+
+	assert.Nil(t, cfg.TracerOptions.Sampler)
+	assert.Equal(t, []string{"xray"}, cfg.Propagators)
+	assert.Zero(t, cfg.MetricOptions.PeriodicInterval)
+	assert.False(t, cfg.Disabled)
+}
+
+func TestNewFromEnvDisabled(t *testing.T) {
+	t.Setenv(EnvSDKDisabled, "true")
+
+	tel, err := NewFromEnv(Config{Service: Service{Name: "test-service"}})
+	require.NoError(t, err)
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	assert.False(t, span.Span().IsRecording())
+	span.End()
+
+	assert.NoError(t, tel.Shutdown(context.Background()))
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		protocol Protocol
+		host     string
+		port     string
+		path     string
+	}{
+		{"http://collector:4318", "", "collector", "4318", ""},
+		{"https://collector", "", "collector", defaultCollectorPort, ""},
+		{"https://collector/", ProtocolHTTPProtobuf, "collector", defaultCollectorHTTPPort, ""},
+		{"http://collector:4318/v1/traces", ProtocolHTTPProtobuf, "collector", "4318", "/v1/traces"},
+		{"collector:4317", "", "collector", "4317", ""},
+		{"collector", "", "collector", defaultCollectorPort, ""},
+	}
+
+	for _, tt := range tests {
+		host, port, path, err := parseEndpoint(tt.endpoint, tt.protocol)
+		assert.NoError(t, err, tt.endpoint)
+		assert.Equal(t, tt.host, host, tt.endpoint)
+		assert.Equal(t, tt.port, port, tt.endpoint)
+		assert.Equal(t, tt.path, path, tt.endpoint)
+	}
+}
+
+func TestConfigFromEnvDefaultEndpoint(t *testing.T) {
+	t.Run("grpc", func(t *testing.T) {
+		t.Setenv(EnvTracesExporter, "otlp")
+
+		cfg := ConfigFromEnv()
+		cfg.Service.Name = "test-service"
+
+		assert.Equal(t, "localhost", cfg.Collector.Host)
+		assert.Equal(t, defaultCollectorPort, cfg.Collector.Port)
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("http/protobuf", func(t *testing.T) {
+		t.Setenv(EnvLogsExporter, "otlp")
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+
+		cfg := ConfigFromEnv()
+
+		assert.Equal(t, "localhost", cfg.Collector.Host)
+		assert.Equal(t, defaultCollectorHTTPPort, cfg.Collector.Port)
+	})
+
+	t.Run("per-signal protocol", func(t *testing.T) {
+		t.Setenv(EnvTracesExporter, "otlp")
+		t.Setenv(EnvMetricsExporter, "otlp")
+		t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/protobuf")
+
+		cfg := ConfigFromEnv()
+
+		assert.Equal(t, defaultCollectorPort, cfg.Collector.Port)
+		require.NotNil(t, cfg.MetricOptions.Collector)
+		assert.Equal(t, defaultCollectorHTTPPort, cfg.Collector.merge(cfg.MetricOptions.Collector).Port)
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		t.Setenv(EnvTracesExporter, "console")
+
+		cfg := ConfigFromEnv()
+
+		assert.Empty(t, cfg.Collector.Host)
+		assert.Empty(t, cfg.Collector.Port)
+	})
+
+	t.Run("explicit endpoint", func(t *testing.T) {
+		t.Setenv(EnvTracesExporter, "otlp")
+		t.Setenv(EnvExporterEndpoint, "collector:4000")
+
+		cfg := ConfigFromEnv()
+
+		assert.Equal(t, "collector", cfg.Collector.Host)
+		assert.Equal(t, "4000", cfg.Collector.Port)
+	})
+}
@@ -0,0 +1,33 @@
+package otelemetry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidConfig is returned (wrapped) by New when the Config fails validation.
+var ErrInvalidConfig = errors.New("otelemetry: invalid config")
+
+// Signal names used in errors.
+const (
+	SignalTraces  = "traces"
+	SignalMetrics = "metrics"
+	SignalLogs    = "logs"
+)
+
+// ExporterError is returned by New when the exporter or provider
+// of a signal cannot be created.
+type ExporterError struct {
+	// Signal is one of SignalTraces, SignalMetrics or SignalLogs.
+	Signal string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ExporterError) Error() string {
+	return fmt.Sprintf("otelemetry: creating %s exporter: %v", e.Signal, e.Err)
+}
+
+func (e *ExporterError) Unwrap() error {
+	return e.Err
+}
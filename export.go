@@ -0,0 +1,118 @@
+package otelemetry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Exporter timeout and retry defaults, the same as the OTLP exporters.
+const (
+	defaultExportTimeout = 10 * time.Second
+
+	defaultRetryInitialInterval = 5 * time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMaxElapsedTime  = time.Minute
+)
+
+// ExportPolicy controls how the OTLP exporters send data, independently of
+// the signal and the protocol. Zero values keep the exporter defaults.
+type ExportPolicy struct {
+	// Compression of the export requests. Defaults to CompressionNone.
+	Compression Compression
+	// Timeout of a single export request. Defaults to 10 seconds.
+	Timeout time.Duration
+	// Retry of failed exports. Defaults to retrying with the RetryPolicy defaults.
+	Retry *RetryPolicy
+}
+
+// RetryPolicy is the exponential backoff applied to failed exports.
+type RetryPolicy struct {
+	// Disabled drops the data after the first failed attempt.
+	Disabled bool
+	// InitialInterval is the wait after the first failure. Defaults to 5 seconds.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between two attempts. Defaults to 30 seconds.
+	MaxInterval time.Duration
+	// MaxElapsedTime is the total time spent retrying before the data is
+	// dropped. Defaults to 1 minute.
+	MaxElapsedTime time.Duration
+}
+
+// retryConfig mirrors the RetryConfig types of the OTLP exporters, which all
+// share this underlying type and convert from it.
+type retryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+func (r *RetryPolicy) config() retryConfig {
+	cfg := retryConfig{
+		Enabled:         !r.Disabled,
+		InitialInterval: r.InitialInterval,
+		MaxInterval:     r.MaxInterval,
+		MaxElapsedTime:  r.MaxElapsedTime,
+	}
+	if cfg.InitialInterval == 0 {
+		cfg.InitialInterval = defaultRetryInitialInterval
+	}
+	if cfg.MaxInterval == 0 {
+		cfg.MaxInterval = defaultRetryMaxInterval
+	}
+	if cfg.MaxElapsedTime == 0 {
+		cfg.MaxElapsedTime = defaultRetryMaxElapsedTime
+	}
+
+	return cfg
+}
+
+// timeout returns the timeout of a single export request.
+func (p ExportPolicy) timeout() time.Duration {
+	if p.Timeout == 0 {
+		return defaultExportTimeout
+	}
+
+	return p.Timeout
+}
+
+// merge returns p with every field set in override replacing its value.
+// The retry policy is replaced as a whole.
+func (p ExportPolicy) merge(override ExportPolicy) ExportPolicy {
+	if override.Compression != "" {
+		p.Compression = override.Compression
+	}
+	if override.Timeout != 0 {
+		p.Timeout = override.Timeout
+	}
+	if override.Retry != nil {
+		p.Retry = override.Retry
+	}
+
+	return p
+}
+
+func (p ExportPolicy) validate() []error {
+	var errs []error
+
+	switch p.Compression {
+	case "", CompressionNone, CompressionGzip:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported collector compression %q", p.Compression))
+	}
+
+	if p.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("collector timeout %v must not be negative", p.Timeout))
+	}
+
+	if p.Retry != nil && !p.Retry.Disabled {
+		if p.Retry.InitialInterval < 0 || p.Retry.MaxInterval < 0 || p.Retry.MaxElapsedTime < 0 {
+			errs = append(errs, errors.New("retry intervals must not be negative"))
+		} else if cfg := p.Retry.config(); cfg.MaxInterval < cfg.InitialInterval {
+			errs = append(errs, fmt.Errorf("retry max interval %v is shorter than the initial interval %v", cfg.MaxInterval, cfg.InitialInterval))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,100 @@
+package otelemetry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyCollector returns an OTLP/HTTP collector answering 503 to the
+// first failures requests, and the counter of received requests.
+func newFlakyCollector(t *testing.T, failures int32) (Collector, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	return Collector{Host: host, Port: port, Protocol: ProtocolHTTPProtobuf}, &requests
+}
+
+func TestExportPolicyRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		retry    *RetryPolicy
+		requests int32
+	}{
+		{"retried", &RetryPolicy{InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond}, 3},
+		{"disabled", &RetryPolicy{Disabled: true}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector, requests := newFlakyCollector(t, 2)
+			collector.ExportPolicy = ExportPolicy{Timeout: time.Second, Retry: tt.retry}
+
+			tel, err := New(Config{
+				Service:    Service{Name: "test-service"},
+				Collector:  collector,
+				WithTraces: true,
+			})
+			require.NoError(t, err)
+
+			_, span := tel.Trace().StartSpan(context.Background(), "span")
+			span.End()
+			_ = tel.Shutdown(context.Background())
+
+			assert.Equal(t, tt.requests, requests.Load())
+		})
+	}
+}
+
+func TestExportPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ExportPolicy
+		err    string
+	}{
+		{"compression", ExportPolicy{Compression: "br"}, `unsupported collector compression "br"`},
+		{"timeout", ExportPolicy{Timeout: -time.Second}, "collector timeout -1s must not be negative"},
+		{"negative retry", ExportPolicy{Retry: &RetryPolicy{MaxElapsedTime: -time.Second}}, "retry intervals must not be negative"},
+		{"retry bounds", ExportPolicy{Retry: &RetryPolicy{InitialInterval: time.Minute}}, "retry max interval 30s is shorter than the initial interval 1m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{
+				Service:    Service{Name: "test-service"},
+				Collector:  Collector{Host: "localhost", Port: "4317", ExportPolicy: tt.policy},
+				WithTraces: true,
+			}.Validate()
+
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestExportPolicyMerge(t *testing.T) {
+	retry := &RetryPolicy{Disabled: true}
+	shared := ExportPolicy{Compression: CompressionGzip, Timeout: time.Second}
+
+	assert.Equal(t, shared, shared.merge(ExportPolicy{}))
+	assert.Equal(t,
+		ExportPolicy{Compression: CompressionGzip, Timeout: 5 * time.Second, Retry: retry},
+		shared.merge(ExportPolicy{Timeout: 5 * time.Second, Retry: retry}),
+	)
+}
@@ -0,0 +1,32 @@
+package otelemetry
+
+import "fmt"
+
+// exporter returns the effective exporter of a signal: mode when set,
+// otherwise ExporterOTLP when the signal is enabled and ExporterStdout when not.
+func exporter(mode Exporter, enabled bool) Exporter {
+	switch {
+	case mode != "":
+		return mode
+	case enabled:
+		return ExporterOTLP
+	default:
+		return ExporterStdout
+	}
+}
+
+// exporters returns the effective exporters of the traces, metrics and logs.
+func (c Config) exporters() (traces, metrics, logs Exporter) {
+	return exporter(c.TracerOptions.Exporter, c.WithTraces),
+		exporter(c.MetricOptions.Exporter, c.WithMetrics),
+		exporter(c.LoggerOptions.Exporter, c.WithLogs)
+}
+
+func (e Exporter) validate(signal string) error {
+	switch e {
+	case "", ExporterOTLP, ExporterStdout, ExporterNone, ExporterMemory:
+		return nil
+	default:
+		return fmt.Errorf("%s: unsupported exporter %q", signal, e)
+	}
+}
@@ -0,0 +1,160 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExporterNone(t *testing.T) {
+	restoreGlobals(t)
+
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		WithTraces:    true,
+		TracerOptions: TracerOptions{Exporter: ExporterNone},
+		MetricOptions: MetricOptions{Exporter: ExporterNone},
+		LoggerOptions: LoggerOptions{Exporter: ExporterNone},
+	})
+	require.NoError(t, err, "no collector is needed when no signal uses OTLP")
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	assert.False(t, span.Span().IsRecording())
+	span.End()
+
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	tel.Log().Info(context.Background(), "message")
+
+	impl := tel.(*telemetry)
+	assert.Nil(t, impl.tracerProvider)
+	assert.Nil(t, impl.meterProvider)
+	assert.Nil(t, impl.loggerProvider)
+	assert.Nil(t, tel.Memory())
+
+	_, global := otel.Tracer("global").Start(context.Background(), "span")
+	assert.False(t, global.IsRecording(), "the global tracer provider is a no-op")
+	global.End()
+
+	assert.NoError(t, tel.Shutdown(context.Background()))
+}
+
+func TestExporterMemory(t *testing.T) {
+	ctx := context.Background()
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Isolated:      true,
+		TracerOptions: TracerOptions{Exporter: ExporterMemory},
+		MetricOptions: MetricOptions{Exporter: ExporterMemory},
+		LoggerOptions: LoggerOptions{Exporter: ExporterMemory},
+	})
+	require.NoError(t, err)
+
+	ctx, span := tel.Trace().StartSpan(ctx, "span")
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 2)
+	tel.Log().Info(ctx, "message")
+	span.End()
+
+	memory := tel.Memory()
+	require.NotNil(t, memory)
+
+	spans := memory.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "span", spans[0].Name)
+
+	logs := memory.Logs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "message", logs[0].Body().AsString())
+	assert.Equal(t, span.Span().SpanContext().TraceID(), logs[0].TraceID())
+
+	rm, err := memory.Metrics(ctx)
+	require.NoError(t, err)
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	sum, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.Equal(t, int64(2), sum.DataPoints[0].Value)
+
+	memory.Reset()
+	assert.Empty(t, memory.Spans())
+	assert.Empty(t, memory.Logs())
+
+	_, span = tel.Trace().StartSpan(ctx, "after reset")
+	span.End()
+	require.NoError(t, tel.Shutdown(context.Background()))
+	assert.Len(t, memory.Spans(), 1, "spans are kept past shutdown")
+}
+
+func TestExporterMemorySingleSignal(t *testing.T) {
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Isolated:      true,
+		TracerOptions: TracerOptions{Exporter: ExporterMemory},
+		LoggerOptions: LoggerOptions{Exporter: ExporterNone},
+		MetricOptions: MetricOptions{Exporter: ExporterNone},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	tel.Log().Info(context.Background(), "message")
+	rm, err := tel.Memory().Metrics(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, rm.ScopeMetrics)
+	assert.Empty(t, tel.Memory().Logs())
+}
+
+func TestExporterValidation(t *testing.T) {
+	err := Config{
+		Service:       Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Exporter: "zipkin"},
+	}.Validate()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, `traces: unsupported exporter "zipkin"`)
+
+	err = Config{
+		Service:       Service{Name: "test-service"},
+		MetricOptions: MetricOptions{Exporter: ExporterOTLP},
+	}.Validate()
+	assert.ErrorContains(t, err, "collector host is required", "an explicit OTLP exporter needs a collector")
+
+	err = Config{
+		Service:       Service{Name: "test-service"},
+		WithLogs:      true,
+		LoggerOptions: LoggerOptions{Exporter: ExporterStdout},
+	}.Validate()
+	assert.NoError(t, err)
+}
+
+func TestNewNoop(t *testing.T) {
+	restoreGlobals(t)
+	before := otel.GetTracerProvider()
+
+	tel := NewNoop()
+
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	ctx, span := tel.Trace().StartSpan(ctx, "span")
+	assert.False(t, span.Span().IsRecording())
+	span.End()
+
+	carrier := map[string]string{}
+	tel.Inject(ctx, carrier)
+	assert.Empty(t, carrier)
+
+	tel.Log().Info(ctx, "message")
+	assert.Nil(t, tel.Memory())
+	assert.NoError(t, tel.ForceFlush(ctx))
+	assert.NoError(t, tel.Shutdown(ctx))
+	assert.Equal(t, before, otel.GetTracerProvider(), "the globals are left alone")
+}
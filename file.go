@@ -0,0 +1,844 @@
+package otelemetry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFromFile loads a Config from a YAML or JSON file following
+// the OpenTelemetry declarative configuration schema (file_format 0.3).
+//
+// Environment variable references of the form ${VAR} or ${VAR:-default}
+// are substituted before parsing. Errors name the offending key, e.g.
+// "tracer_provider.processors[0].batch.exporter: exactly one exporter must be set".
+//
+// Only the subset of the schema that maps onto Config is supported:
+// one processor (or reader) per signal, OTLP and console exporters, the
+// built-in samplers, metric views and the propagators of Config.Propagators.
+func ConfigFromFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config file: %w", err)
+	}
+
+	cfg, err := parseConfigFile(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// NewFromFile creates a new Telemetry instance from the configuration file at path.
+// See ConfigFromFile for the supported format.
+func NewFromFile(path string) (Telemetry, error) {
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(cfg)
+}
+
+// supportedFileFormats lists the file_format versions understood by ConfigFromFile.
+var supportedFileFormats = []string{"0.1", "0.2", "0.3"}
+
+type fileConfig struct {
+	FileFormat     string              `yaml:"file_format"`
+	Disabled       bool                `yaml:"disabled"`
+	Resource       *fileResource       `yaml:"resource"`
+	Propagator     *filePropagator     `yaml:"propagator"`
+	TracerProvider *fileTracerProvider `yaml:"tracer_provider"`
+	MeterProvider  *fileMeterProvider  `yaml:"meter_provider"`
+	LoggerProvider *fileLoggerProvider `yaml:"logger_provider"`
+}
+
+type fileResource struct {
+	Attributes     []fileAttribute `yaml:"attributes"`
+	AttributesList string          `yaml:"attributes_list"`
+	SchemaURL      string          `yaml:"schema_url"`
+}
+
+type fileAttribute struct {
+	Name  string `yaml:"name"`
+	Value any    `yaml:"value"`
+	Type  string `yaml:"type"`
+}
+
+type filePropagator struct {
+	Composite []string `yaml:"composite"`
+}
+
+type fileTracerProvider struct {
+	Processors []fileProcessor `yaml:"processors"`
+	Sampler    *fileSampler    `yaml:"sampler"`
+}
+
+type fileProcessor struct {
+	Batch  *fileBatchProcessor `yaml:"batch"`
+	Simple *fileSimple         `yaml:"simple"`
+}
+
+type fileBatchProcessor struct {
+	ScheduleDelay      *int         `yaml:"schedule_delay"`
+	ExportTimeout      *int         `yaml:"export_timeout"`
+	MaxQueueSize       *int         `yaml:"max_queue_size"`
+	MaxExportBatchSize *int         `yaml:"max_export_batch_size"`
+	Exporter           fileExporter `yaml:"exporter"`
+}
+
+type fileSimple struct {
+	Exporter fileExporter `yaml:"exporter"`
+}
+
+type fileExporter struct {
+	OTLP    *fileOTLP `yaml:"otlp"`
+	Console *struct{} `yaml:"console"`
+}
+
+type fileOTLP struct {
+	Protocol          string       `yaml:"protocol"`
+	Endpoint          string       `yaml:"endpoint"`
+	Certificate       string       `yaml:"certificate"`
+	ClientCertificate string       `yaml:"client_certificate"`
+	ClientKey         string       `yaml:"client_key"`
+	Insecure          bool         `yaml:"insecure"`
+	Headers           []fileHeader `yaml:"headers"`
+	HeadersList       string       `yaml:"headers_list"`
+	Compression       string       `yaml:"compression"`
+	Timeout           *int         `yaml:"timeout"`
+}
+
+type fileHeader struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// tls returns the TLS settings of the exporter, nil for plaintext.
+func (o *fileOTLP) tls() *TLS {
+	if o.Insecure {
+		return nil
+	}
+	if o.Certificate == "" && o.ClientCertificate == "" && o.ClientKey == "" &&
+		!strings.HasPrefix(strings.ToLower(o.Endpoint), "https://") {
+		return nil
+	}
+
+	return &TLS{CAFile: o.Certificate, CertFile: o.ClientCertificate, KeyFile: o.ClientKey}
+}
+
+type fileSampler struct {
+	AlwaysOn          *struct{}              `yaml:"always_on"`
+	AlwaysOff         *struct{}              `yaml:"always_off"`
+	TraceIDRatioBased *fileTraceIDRatio      `yaml:"trace_id_ratio_based"`
+	ParentBased       *fileParentBasedSample `yaml:"parent_based"`
+}
+
+type fileTraceIDRatio struct {
+	Ratio *float64 `yaml:"ratio"`
+}
+
+type fileParentBasedSample struct {
+	Root                   *fileSampler `yaml:"root"`
+	RemoteParentSampled    *fileSampler `yaml:"remote_parent_sampled"`
+	RemoteParentNotSampled *fileSampler `yaml:"remote_parent_not_sampled"`
+	LocalParentSampled     *fileSampler `yaml:"local_parent_sampled"`
+	LocalParentNotSampled  *fileSampler `yaml:"local_parent_not_sampled"`
+}
+
+type fileMeterProvider struct {
+	Readers []fileReader `yaml:"readers"`
+	Views   []fileView   `yaml:"views"`
+}
+
+type fileReader struct {
+	Periodic *filePeriodicReader `yaml:"periodic"`
+	Pull     *struct{}           `yaml:"pull"`
+}
+
+type filePeriodicReader struct {
+	Interval *int         `yaml:"interval"`
+	Exporter fileExporter `yaml:"exporter"`
+}
+
+type fileView struct {
+	Selector fileViewSelector `yaml:"selector"`
+	Stream   fileViewStream   `yaml:"stream"`
+}
+
+type fileViewSelector struct {
+	InstrumentName string `yaml:"instrument_name"`
+	InstrumentType string `yaml:"instrument_type"`
+	Unit           string `yaml:"unit"`
+	MeterName      string `yaml:"meter_name"`
+	MeterVersion   string `yaml:"meter_version"`
+	MeterSchemaURL string `yaml:"meter_schema_url"`
+}
+
+type fileViewStream struct {
+	Name          string             `yaml:"name"`
+	Description   string             `yaml:"description"`
+	Aggregation   *fileAggregation   `yaml:"aggregation"`
+	AttributeKeys *fileAttributeKeys `yaml:"attribute_keys"`
+}
+
+type fileAggregation struct {
+	Default                         *struct{}                 `yaml:"default"`
+	Drop                            *struct{}                 `yaml:"drop"`
+	Sum                             *struct{}                 `yaml:"sum"`
+	LastValue                       *struct{}                 `yaml:"last_value"`
+	ExplicitBucketHistogram         *fileExplicitHistogram    `yaml:"explicit_bucket_histogram"`
+	Base2ExponentialBucketHistogram *fileExponentialHistogram `yaml:"base2_exponential_bucket_histogram"`
+}
+
+type fileExplicitHistogram struct {
+	Boundaries   []float64 `yaml:"boundaries"`
+	RecordMinMax *bool     `yaml:"record_min_max"`
+}
+
+type fileExponentialHistogram struct {
+	MaxScale     *int32 `yaml:"max_scale"`
+	MaxSize      *int32 `yaml:"max_size"`
+	RecordMinMax *bool  `yaml:"record_min_max"`
+}
+
+type fileAttributeKeys struct {
+	Included []string `yaml:"included"`
+	Excluded []string `yaml:"excluded"`
+}
+
+type fileLoggerProvider struct {
+	Processors []fileProcessor `yaml:"processors"`
+}
+
+// envRefPattern matches ${VAR}, ${env:VAR} and ${VAR:-default}.
+var envRefPattern = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv substitutes environment variable references in data.
+func expandEnv(data []byte) []byte {
+	return envRefPattern.ReplaceAllFunc(data, func(ref []byte) []byte {
+		m := envRefPattern.FindSubmatch(ref)
+		if v, ok := os.LookupEnv(string(m[1])); ok {
+			return []byte(v)
+		}
+		return m[2]
+	})
+}
+
+func parseConfigFile(data []byte) (Config, error) {
+	var fc fileConfig
+
+	dec := yaml.NewDecoder(bytes.NewReader(expandEnv(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil {
+		return Config{}, fmt.Errorf("decoding config: %w", err)
+	}
+
+	return fc.toConfig()
+}
+
+func (fc *fileConfig) toConfig() (Config, error) {
+	cfg := Config{Disabled: fc.Disabled}
+
+	if fc.FileFormat == "" {
+		return cfg, keyErr("file_format", errors.New("required"))
+	}
+	if !slices.Contains(supportedFileFormats, fc.FileFormat) {
+		return cfg, keyErr("file_format", fmt.Errorf("unsupported version %q", fc.FileFormat))
+	}
+
+	if fc.Resource != nil {
+		if err := fc.Resource.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if fc.Propagator != nil {
+		for i, name := range fc.Propagator.Composite {
+			if _, ok := propagators[name]; !ok {
+				return cfg, keyErr(fmt.Sprintf("propagator.composite[%d]", i), fmt.Errorf("unsupported propagator %q", name))
+			}
+		}
+		cfg.Propagators = fc.Propagator.Composite
+	}
+
+	if fc.TracerProvider != nil {
+		if err := fc.TracerProvider.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if fc.MeterProvider != nil {
+		if err := fc.MeterProvider.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if fc.LoggerProvider != nil {
+		if err := fc.LoggerProvider.apply(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func (r *fileResource) apply(cfg *Config) error {
+	attrs := make(map[string]attribute.KeyValue)
+
+	if r.AttributesList != "" {
+		list, err := parseResourceAttributes(r.AttributesList)
+		if err != nil {
+			return keyErr("resource.attributes_list", err)
+		}
+		for k, v := range list {
+			attrs[k] = attribute.String(k, v)
+		}
+	}
+
+	// attributes take precedence over attributes_list
+	for i, a := range r.Attributes {
+		key := fmt.Sprintf("resource.attributes[%d]", i)
+		if a.Name == "" {
+			return keyErr(key+".name", errors.New("required"))
+		}
+		kv, err := fileAttributeValue(a)
+		if err != nil {
+			return keyErr(key+".value", err)
+		}
+		attrs[a.Name] = kv
+	}
+
+	service := map[attribute.Key]*string{
+		semconv.ServiceNameKey:      &cfg.Service.Name,
+		semconv.ServiceNamespaceKey: &cfg.Service.Namespace,
+		semconv.ServiceVersionKey:   &cfg.Service.Version,
+	}
+	for k, dst := range service {
+		if kv, ok := attrs[string(k)]; ok {
+			*dst = kv.Value.Emit()
+			delete(attrs, string(k))
+		}
+	}
+
+	var opts []sdkresource.Option
+	if len(attrs) > 0 {
+		kv := make([]attribute.KeyValue, 0, len(attrs))
+		for _, a := range attrs {
+			kv = append(kv, a)
+		}
+		opts = append(opts, sdkresource.WithAttributes(kv...))
+	}
+	if r.SchemaURL != "" {
+		opts = append(opts, sdkresource.WithSchemaURL(r.SchemaURL))
+	}
+	cfg.ResourceOptions = append(cfg.ResourceOptions, opts...)
+
+	return nil
+}
+
+func fileAttributeValue(a fileAttribute) (attribute.KeyValue, error) {
+	switch a.Type {
+	case "", "string", "bool", "int", "double":
+	case "string_array", "bool_array", "int_array", "double_array":
+	default:
+		return attribute.KeyValue{}, fmt.Errorf("unsupported type %q", a.Type)
+	}
+
+	switch v := a.Value.(type) {
+	case string:
+		if a.Type == "" || a.Type == "string" {
+			return attribute.String(a.Name, v), nil
+		}
+	case bool:
+		if a.Type == "" || a.Type == "bool" {
+			return attribute.Bool(a.Name, v), nil
+		}
+	case int:
+		switch a.Type {
+		case "", "int":
+			return attribute.Int(a.Name, v), nil
+		case "double":
+			return attribute.Float64(a.Name, float64(v)), nil
+		}
+	case float64:
+		if a.Type == "" || a.Type == "double" {
+			return attribute.Float64(a.Name, v), nil
+		}
+	case []any:
+		return fileAttributeSlice(a.Name, a.Type, v)
+	case nil:
+		return attribute.KeyValue{}, errors.New("required")
+	}
+
+	return attribute.KeyValue{}, fmt.Errorf("value %v does not match type %q", a.Value, a.Type)
+}
+
+func fileAttributeSlice(name, typ string, values []any) (attribute.KeyValue, error) {
+	var (
+		strs   []string
+		bools  []bool
+		ints   []int
+		floats []float64
+	)
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			strs = append(strs, v)
+		case bool:
+			bools = append(bools, v)
+		case int:
+			ints = append(ints, v)
+			floats = append(floats, float64(v))
+		case float64:
+			floats = append(floats, v)
+		}
+	}
+
+	switch {
+	case len(strs) == len(values) && (typ == "" || typ == "string_array"):
+		return attribute.StringSlice(name, strs), nil
+	case len(bools) == len(values) && (typ == "" || typ == "bool_array"):
+		return attribute.BoolSlice(name, bools), nil
+	case len(ints) == len(values) && (typ == "" || typ == "int_array"):
+		return attribute.IntSlice(name, ints), nil
+	case len(floats) == len(values) && (typ == "" || typ == "double_array"):
+		return attribute.Float64Slice(name, floats), nil
+	}
+
+	return attribute.KeyValue{}, fmt.Errorf("array values do not match type %q", typ)
+}
+
+func (tp *fileTracerProvider) apply(cfg *Config) error {
+	if tp.Sampler != nil {
+		sampler, err := tp.Sampler.sampler("tracer_provider.sampler")
+		if err != nil {
+			return err
+		}
+		cfg.TracerOptions.Sampler = sampler
+	}
+
+	batch, err := singleBatchProcessor("tracer_provider.processors", tp.Processors)
+	if err != nil || batch == nil {
+		return err
+	}
+
+	if batch.ScheduleDelay != nil {
+		cfg.TracerOptions.BatchSpanProcessorOption = append(cfg.TracerOptions.BatchSpanProcessorOption,
+			sdktrace.WithBatchTimeout(millis(*batch.ScheduleDelay)))
+	}
+	if batch.ExportTimeout != nil {
+		cfg.TracerOptions.BatchSpanProcessorOption = append(cfg.TracerOptions.BatchSpanProcessorOption,
+			sdktrace.WithExportTimeout(millis(*batch.ExportTimeout)))
+	}
+	if batch.MaxQueueSize != nil {
+		cfg.TracerOptions.BatchSpanProcessorOption = append(cfg.TracerOptions.BatchSpanProcessorOption,
+			sdktrace.WithMaxQueueSize(*batch.MaxQueueSize))
+	}
+	if batch.MaxExportBatchSize != nil {
+		cfg.TracerOptions.BatchSpanProcessorOption = append(cfg.TracerOptions.BatchSpanProcessorOption,
+			sdktrace.WithMaxExportBatchSize(*batch.MaxExportBatchSize))
+	}
+
+	collector, path, err := batch.Exporter.apply("tracer_provider.processors[0].batch.exporter", &cfg.WithTraces)
+	if err != nil {
+		return err
+	}
+	cfg.TracerOptions.Collector = collector
+	cfg.TracerOptions.URLPath = path
+
+	return nil
+}
+
+func (mp *fileMeterProvider) apply(cfg *Config) error {
+	for i, v := range mp.Views {
+		view, err := v.view(fmt.Sprintf("meter_provider.views[%d]", i))
+		if err != nil {
+			return err
+		}
+		cfg.MetricOptions.ProviderOptions = append(cfg.MetricOptions.ProviderOptions, sdkmetric.WithView(view))
+	}
+
+	switch {
+	case len(mp.Readers) == 0:
+		return nil
+	case len(mp.Readers) > 1:
+		return keyErr("meter_provider.readers", errors.New("only one reader is supported"))
+	case mp.Readers[0].Pull != nil:
+		return keyErr("meter_provider.readers[0].pull", errors.New("pull readers are not supported"))
+	case mp.Readers[0].Periodic == nil:
+		return keyErr("meter_provider.readers[0]", errors.New("periodic reader is required"))
+	}
+
+	periodic := mp.Readers[0].Periodic
+	if periodic.Interval != nil {
+		if *periodic.Interval <= 0 {
+			return keyErr("meter_provider.readers[0].periodic.interval", errors.New("must be positive"))
+		}
+		cfg.MetricOptions.PeriodicInterval = millis(*periodic.Interval)
+	}
+
+	collector, path, err := periodic.Exporter.apply("meter_provider.readers[0].periodic.exporter", &cfg.WithMetrics)
+	if err != nil {
+		return err
+	}
+	cfg.MetricOptions.Collector = collector
+	cfg.MetricOptions.URLPath = path
+
+	return nil
+}
+
+func (lp *fileLoggerProvider) apply(cfg *Config) error {
+	batch, err := singleBatchProcessor("logger_provider.processors", lp.Processors)
+	if err != nil || batch == nil {
+		return err
+	}
+
+	if batch.ScheduleDelay != nil {
+		cfg.LoggerOptions.BatchProcessorOption = append(cfg.LoggerOptions.BatchProcessorOption,
+			sdklog.WithExportInterval(millis(*batch.ScheduleDelay)))
+	}
+	if batch.ExportTimeout != nil {
+		cfg.LoggerOptions.BatchProcessorOption = append(cfg.LoggerOptions.BatchProcessorOption,
+			sdklog.WithExportTimeout(millis(*batch.ExportTimeout)))
+	}
+	if batch.MaxQueueSize != nil {
+		cfg.LoggerOptions.BatchProcessorOption = append(cfg.LoggerOptions.BatchProcessorOption,
+			sdklog.WithMaxQueueSize(*batch.MaxQueueSize))
+	}
+	if batch.MaxExportBatchSize != nil {
+		cfg.LoggerOptions.BatchProcessorOption = append(cfg.LoggerOptions.BatchProcessorOption,
+			sdklog.WithExportMaxBatchSize(*batch.MaxExportBatchSize))
+	}
+
+	collector, path, err := batch.Exporter.apply("logger_provider.processors[0].batch.exporter", &cfg.WithLogs)
+	if err != nil {
+		return err
+	}
+	cfg.LoggerOptions.Collector = collector
+	cfg.LoggerOptions.URLPath = path
+
+	return nil
+}
+
+// singleBatchProcessor returns the only batch processor of a pipeline,
+// as Config holds a single pipeline per signal.
+func singleBatchProcessor(key string, processors []fileProcessor) (*fileBatchProcessor, error) {
+	switch {
+	case len(processors) == 0:
+		return nil, nil
+	case len(processors) > 1:
+		return nil, keyErr(key, errors.New("only one processor is supported"))
+	case processors[0].Simple != nil:
+		return nil, keyErr(key+"[0].simple", errors.New("simple processors are not supported"))
+	case processors[0].Batch == nil:
+		return nil, keyErr(key+"[0]", errors.New("batch processor is required"))
+	}
+
+	batch := processors[0].Batch
+	for _, f := range []struct {
+		name  string
+		value *int
+	}{
+		{"schedule_delay", batch.ScheduleDelay},
+		{"export_timeout", batch.ExportTimeout},
+		{"max_queue_size", batch.MaxQueueSize},
+		{"max_export_batch_size", batch.MaxExportBatchSize},
+	} {
+		if f.value != nil && *f.value <= 0 {
+			return nil, keyErr(key+"[0].batch."+f.name, errors.New("must be positive"))
+		}
+	}
+
+	return batch, nil
+}
+
+// apply enables the signal's OTLP pipeline and returns the collector the
+// signal exports to, used as the signal's Collector override, and the URL
+// path of the endpoint, if any. The collector is nil for the console exporter.
+func (e fileExporter) apply(key string, enabled *bool) (*Collector, string, error) {
+	switch {
+	case e.OTLP != nil && e.Console != nil, e.OTLP == nil && e.Console == nil:
+		return nil, "", keyErr(key, errors.New("exactly one exporter must be set"))
+	case e.Console != nil:
+		*enabled = false
+		return nil, "", nil
+	}
+
+	*enabled = true
+	key += ".otlp"
+
+	collector := &Collector{
+		Protocol:     Protocol(e.OTLP.Protocol),
+		TLS:          e.OTLP.tls(),
+		ExportPolicy: ExportPolicy{Compression: Compression(e.OTLP.Compression)},
+	}
+	switch collector.Protocol {
+	case "":
+		collector.Protocol = ProtocolGRPC
+	case ProtocolGRPC, ProtocolHTTPProtobuf, ProtocolHTTPJSON:
+	default:
+		return nil, "", keyErr(key+".protocol", fmt.Errorf("unsupported protocol %q", e.OTLP.Protocol))
+	}
+
+	switch collector.Compression {
+	case "", CompressionNone, CompressionGzip:
+	default:
+		return nil, "", keyErr(key+".compression", fmt.Errorf("unsupported compression %q", e.OTLP.Compression))
+	}
+
+	if e.OTLP.Timeout != nil {
+		if *e.OTLP.Timeout <= 0 {
+			return nil, "", keyErr(key+".timeout", errors.New("must be positive"))
+		}
+		collector.Timeout = millis(*e.OTLP.Timeout)
+	}
+
+	headers, err := e.OTLP.headers(key)
+	if err != nil {
+		return nil, "", err
+	}
+	collector.Headers = headers
+
+	endpoint := e.OTLP.Endpoint
+	if endpoint == "" {
+		endpoint = "localhost"
+	}
+
+	host, port, path, err := parseEndpoint(endpoint, collector.Protocol)
+	if err != nil {
+		return nil, "", keyErr(key+".endpoint", err)
+	}
+	collector.Host, collector.Port = host, port
+
+	return collector, path, nil
+}
+
+// headers merges headers_list and headers, the latter taking precedence.
+func (o *fileOTLP) headers(key string) (map[string]string, error) {
+	headers := make(map[string]string)
+
+	if o.HeadersList != "" {
+		list, err := parseResourceAttributes(o.HeadersList)
+		if err != nil {
+			return nil, keyErr(key+".headers_list", err)
+		}
+		headers = list
+	}
+
+	for i, h := range o.Headers {
+		if h.Name == "" {
+			return nil, keyErr(fmt.Sprintf("%s.headers[%d].name", key, i), errors.New("required"))
+		}
+		headers[h.Name] = h.Value
+	}
+
+	if len(headers) == 0 {
+		return nil, nil
+	}
+
+	return headers, nil
+}
+
+func (s *fileSampler) sampler(key string) (*Sampler, error) {
+	set := 0
+	for _, v := range []bool{s.AlwaysOn != nil, s.AlwaysOff != nil, s.TraceIDRatioBased != nil, s.ParentBased != nil} {
+		if v {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, keyErr(key, errors.New("exactly one sampler must be set"))
+	}
+
+	switch {
+	case s.AlwaysOn != nil:
+		return &Sampler{Type: SamplerAlwaysOn}, nil
+	case s.AlwaysOff != nil:
+		return &Sampler{Type: SamplerAlwaysOff}, nil
+	case s.TraceIDRatioBased != nil:
+		ratio := 1.0
+		if s.TraceIDRatioBased.Ratio != nil {
+			ratio = *s.TraceIDRatioBased.Ratio
+		}
+		if ratio < 0 || ratio > 1 {
+			return nil, keyErr(key+".trace_id_ratio_based.ratio", fmt.Errorf("ratio %v out of range [0, 1]", ratio))
+		}
+		return &Sampler{Type: SamplerTraceIDRatio, Ratio: ratio}, nil
+	}
+
+	pb := s.ParentBased
+	key += ".parent_based"
+
+	sampler := &Sampler{Type: SamplerParentBased}
+	for _, o := range []struct {
+		name   string
+		sample *fileSampler
+		dst    **Sampler
+	}{
+		{"root", pb.Root, &sampler.Root},
+		{"remote_parent_sampled", pb.RemoteParentSampled, &sampler.RemoteParentSampled},
+		{"remote_parent_not_sampled", pb.RemoteParentNotSampled, &sampler.RemoteParentNotSampled},
+		{"local_parent_sampled", pb.LocalParentSampled, &sampler.LocalParentSampled},
+		{"local_parent_not_sampled", pb.LocalParentNotSampled, &sampler.LocalParentNotSampled},
+	} {
+		if o.sample == nil {
+			continue
+		}
+		nested, err := o.sample.sampler(key + "." + o.name)
+		if err != nil {
+			return nil, err
+		}
+		*o.dst = nested
+	}
+
+	return sampler, nil
+}
+
+var instrumentKinds = map[string]sdkmetric.InstrumentKind{
+	"counter":                    sdkmetric.InstrumentKindCounter,
+	"up_down_counter":            sdkmetric.InstrumentKindUpDownCounter,
+	"histogram":                  sdkmetric.InstrumentKindHistogram,
+	"gauge":                      sdkmetric.InstrumentKindGauge,
+	"observable_counter":         sdkmetric.InstrumentKindObservableCounter,
+	"observable_up_down_counter": sdkmetric.InstrumentKindObservableUpDownCounter,
+	"observable_gauge":           sdkmetric.InstrumentKindObservableGauge,
+}
+
+func (v fileView) view(key string) (sdkmetric.View, error) {
+	criteria := sdkmetric.Instrument{
+		Name: v.Selector.InstrumentName,
+		Unit: v.Selector.Unit,
+		Scope: instrumentation.Scope{
+			Name:      v.Selector.MeterName,
+			Version:   v.Selector.MeterVersion,
+			SchemaURL: v.Selector.MeterSchemaURL,
+		},
+	}
+	if v.Selector.InstrumentType != "" {
+		kind, ok := instrumentKinds[v.Selector.InstrumentType]
+		if !ok {
+			return nil, keyErr(key+".selector.instrument_type", fmt.Errorf("unsupported instrument type %q", v.Selector.InstrumentType))
+		}
+		criteria.Kind = kind
+	}
+	if criteria.IsEmpty() {
+		return nil, keyErr(key+".selector", errors.New("at least one criterion is required"))
+	}
+	if v.Stream.Name != "" && strings.ContainsAny(criteria.Name, "*?") {
+		return nil, keyErr(key+".stream.name", errors.New("cannot rename instruments matched by a wildcard"))
+	}
+
+	stream := sdkmetric.Stream{
+		Name:        v.Stream.Name,
+		Description: v.Stream.Description,
+	}
+
+	if v.Stream.Aggregation != nil {
+		agg, err := v.Stream.Aggregation.aggregation(key + ".stream.aggregation")
+		if err != nil {
+			return nil, err
+		}
+		stream.Aggregation = agg
+	}
+
+	if keys := v.Stream.AttributeKeys; keys != nil {
+		if len(keys.Included) > 0 && len(keys.Excluded) > 0 {
+			return nil, keyErr(key+".stream.attribute_keys", errors.New("included and excluded are mutually exclusive"))
+		}
+		if len(keys.Included) > 0 {
+			stream.AttributeFilter = attribute.NewAllowKeysFilter(attributeKeys(keys.Included)...)
+		}
+		if len(keys.Excluded) > 0 {
+			stream.AttributeFilter = attribute.NewDenyKeysFilter(attributeKeys(keys.Excluded)...)
+		}
+	}
+
+	return sdkmetric.NewView(criteria, stream), nil
+}
+
+func (a *fileAggregation) aggregation(key string) (sdkmetric.Aggregation, error) {
+	var aggs []sdkmetric.Aggregation
+
+	if a.Default != nil {
+		aggs = append(aggs, sdkmetric.AggregationDefault{})
+	}
+	if a.Drop != nil {
+		aggs = append(aggs, sdkmetric.AggregationDrop{})
+	}
+	if a.Sum != nil {
+		aggs = append(aggs, sdkmetric.AggregationSum{})
+	}
+	if a.LastValue != nil {
+		aggs = append(aggs, sdkmetric.AggregationLastValue{})
+	}
+	if h := a.ExplicitBucketHistogram; h != nil {
+		for i := 1; i < len(h.Boundaries); i++ {
+			if h.Boundaries[i] <= h.Boundaries[i-1] {
+				return nil, keyErr(key+".explicit_bucket_histogram.boundaries", errors.New("must be strictly increasing"))
+			}
+		}
+		aggs = append(aggs, sdkmetric.AggregationExplicitBucketHistogram{
+			Boundaries: h.Boundaries,
+			NoMinMax:   h.RecordMinMax != nil && !*h.RecordMinMax,
+		})
+	}
+	if h := a.Base2ExponentialBucketHistogram; h != nil {
+		agg := sdkmetric.AggregationBase2ExponentialHistogram{
+			MaxSize:  160,
+			MaxScale: 20,
+			NoMinMax: h.RecordMinMax != nil && !*h.RecordMinMax,
+		}
+		if h.MaxSize != nil {
+			agg.MaxSize = *h.MaxSize
+		}
+		if h.MaxScale != nil {
+			agg.MaxScale = *h.MaxScale
+		}
+		if agg.MaxScale < -10 || agg.MaxScale > 20 {
+			return nil, keyErr(key+".base2_exponential_bucket_histogram.max_scale", errors.New("must be in range [-10, 20]"))
+		}
+		if agg.MaxSize <= 0 {
+			return nil, keyErr(key+".base2_exponential_bucket_histogram.max_size", errors.New("must be positive"))
+		}
+		aggs = append(aggs, agg)
+	}
+
+	if len(aggs) != 1 {
+		return nil, keyErr(key, errors.New("exactly one aggregation must be set"))
+	}
+
+	return aggs[0], nil
+}
+
+func attributeKeys(keys []string) []attribute.Key {
+	out := make([]attribute.Key, len(keys))
+	for i, k := range keys {
+		out[i] = attribute.Key(k)
+	}
+	return out
+}
+
+func millis(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func keyErr(key string, err error) error {
+	return fmt.Errorf("%s: %w", key, err)
+}
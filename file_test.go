@@ -0,0 +1,193 @@
+package otelemetry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+file_format: "0.3"
+resource:
+  attributes:
+    - name: service.name
+      value: file-service
+    - name: service.version
+      value: ${SERVICE_VERSION:-0.0.1}
+    - name: replicas
+      value: 3
+      type: int
+propagator:
+  composite: [tracecontext, baggage]
+tracer_provider:
+  sampler:
+    parent_based:
+      root:
+        trace_id_ratio_based:
+          ratio: 0.5
+  processors:
+    - batch:
+        schedule_delay: 200
+        exporter:
+          otlp:
+            protocol: grpc
+            endpoint: http://collector:4317
+meter_provider:
+  readers:
+    - periodic:
+        interval: 1000
+        exporter:
+          otlp:
+            endpoint: http://metrics-gateway:4317
+            compression: gzip
+            timeout: 5000
+            headers_list: tenant=acme
+            headers:
+              - name: api-key
+                value: secret
+  views:
+    - selector:
+        instrument_name: http.server.duration
+      stream:
+        aggregation:
+          explicit_bucket_histogram:
+            boundaries: [0.1, 0.5, 1]
+logger_provider:
+  processors:
+    - batch:
+        exporter:
+          console: {}
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigFromFile(t *testing.T) {
+	t.Setenv("SERVICE_VERSION", "2.1.0")
+
+	cfg, err := ConfigFromFile(writeConfigFile(t, "otel.yaml", testConfigFile))
+	require.NoError(t, err)
+
+	assert.Equal(t, "file-service", cfg.Service.Name)
+	assert.Equal(t, "2.1.0", cfg.Service.Version)
+	assert.Equal(t, []string{"tracecontext", "baggage"}, cfg.Propagators)
+	assert.Equal(t, &Collector{Host: "collector", Port: "4317", Protocol: ProtocolGRPC}, cfg.TracerOptions.Collector)
+	assert.Equal(t, &Collector{
+		Host:         "metrics-gateway",
+		Port:         "4317",
+		Protocol:     ProtocolGRPC,
+		Headers:      map[string]string{"tenant": "acme", "api-key": "secret"},
+		ExportPolicy: ExportPolicy{Timeout: 5 * time.Second, Compression: CompressionGzip},
+	}, cfg.MetricOptions.Collector)
+	assert.Nil(t, cfg.LoggerOptions.Collector)
+	assert.True(t, cfg.WithTraces)
+	assert.True(t, cfg.WithMetrics)
+	assert.False(t, cfg.WithLogs)
+	assert.Equal(t, &Sampler{Type: SamplerParentBased, Root: &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.5}}, cfg.TracerOptions.Sampler)
+	assert.Len(t, cfg.TracerOptions.BatchSpanProcessorOption, 1)
+	assert.Len(t, cfg.MetricOptions.ProviderOptions, 1)
+	assert.Equal(t, time.Second, cfg.MetricOptions.PeriodicInterval)
+	assert.Len(t, cfg.ResourceOptions, 1)
+}
+
+func TestConfigFromFileJSON(t *testing.T) {
+	content := `{
+		"file_format": "0.3",
+		"resource": {"attributes_list": "service.name=json-service,team=core"},
+		"tracer_provider": {"processors": [{"batch": {"exporter": {"console": {}}}}]}
+	}`
+
+	cfg, err := ConfigFromFile(writeConfigFile(t, "otel.json", content))
+	require.NoError(t, err)
+
+	assert.Equal(t, "json-service", cfg.Service.Name)
+	assert.False(t, cfg.WithTraces)
+}
+
+func TestConfigFromFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "missing file format",
+			content: `disabled: true`,
+			err:     "file_format: required",
+		},
+		{
+			name:    "unknown key",
+			content: "file_format: \"0.3\"\ntracer_provider:\n  exporters: []",
+			err:     "field exporters not found",
+		},
+		{
+			name: "two exporters",
+			content: `
+file_format: "0.3"
+tracer_provider:
+  processors:
+    - batch:
+        exporter:
+          otlp: {}
+          console: {}`,
+			err: "tracer_provider.processors[0].batch.exporter: exactly one exporter must be set",
+		},
+		{
+			name: "ratio out of range",
+			content: `
+file_format: "0.3"
+tracer_provider:
+  sampler:
+    parent_based:
+      root:
+        trace_id_ratio_based:
+          ratio: 2`,
+			err: "tracer_provider.sampler.parent_based.root.trace_id_ratio_based.ratio",
+		},
+		{
+			name: "unsupported propagator",
+			content: `
+file_format: "0.3"
+propagator:
+  composite: [tracecontext, carrier-pigeon]`,
+			err: "propagator.composite[1]: unsupported propagator",
+		},
+		{
+			name: "unordered histogram boundaries",
+			content: `
+file_format: "0.3"
+meter_provider:
+  views:
+    - selector:
+        instrument_name: latency
+      stream:
+        aggregation:
+          explicit_bucket_histogram:
+            boundaries: [1, 0.5]`,
+			err: "meter_provider.views[0].stream.aggregation.explicit_bucket_histogram.boundaries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigFromFile(writeConfigFile(t, "otel.yaml", tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestNewFromFileDisabled(t *testing.T) {
+	tel, err := NewFromFile(writeConfigFile(t, "otel.yaml", "file_format: \"0.3\"\ndisabled: true\n"))
+	require.NoError(t, err)
+	assert.NotNil(t, tel.Trace())
+}
@@ -0,0 +1,79 @@
+package otelemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewDeterministicIDGenerator returns an IDGenerator drawing the IDs from a
+// random source seeded with seed: the same seed and the same sequence of
+// spans give the same IDs, as golden tests need.
+func NewDeterministicIDGenerator(seed int64) sdktrace.IDGenerator {
+	return &randomIDGenerator{rand: rand.New(rand.NewSource(seed))}
+}
+
+// NewXRayIDGenerator returns an IDGenerator of AWS X-Ray compatible trace
+// IDs, whose first 4 bytes are the start time in Unix seconds read from
+// clock, the system clock when nil.
+func NewXRayIDGenerator(clock Clock) sdktrace.IDGenerator {
+	return &xrayIDGenerator{
+		randomIDGenerator: randomIDGenerator{rand: rand.New(rand.NewSource(time.Now().UnixNano()))},
+		clock:             clock,
+	}
+}
+
+type randomIDGenerator struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (g *randomIDGenerator) NewIDs(context.Context) (trace.TraceID, trace.SpanID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var traceID trace.TraceID
+	for !traceID.IsValid() {
+		_, _ = g.rand.Read(traceID[:])
+	}
+
+	return traceID, g.spanID()
+}
+
+func (g *randomIDGenerator) NewSpanID(context.Context, trace.TraceID) trace.SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.spanID()
+}
+
+func (g *randomIDGenerator) spanID() trace.SpanID {
+	var spanID trace.SpanID
+	for !spanID.IsValid() {
+		_, _ = g.rand.Read(spanID[:])
+	}
+
+	return spanID
+}
+
+type xrayIDGenerator struct {
+	randomIDGenerator
+	clock Clock
+}
+
+func (g *xrayIDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	now := time.Now()
+	if g.clock != nil {
+		now = g.clock.Now()
+	}
+
+	traceID, spanID := g.randomIDGenerator.NewIDs(ctx)
+	binary.BigEndian.PutUint32(traceID[:4], uint32(now.Unix()))
+
+	return traceID, spanID
+}
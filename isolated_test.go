@@ -0,0 +1,91 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// restoreGlobals puts back the OpenTelemetry globals once the test ends.
+func restoreGlobals(t *testing.T) {
+	var (
+		tracerProvider = otel.GetTracerProvider()
+		meterProvider  = otel.GetMeterProvider()
+		loggerProvider = global.GetLoggerProvider()
+		propagator     = otel.GetTextMapPropagator()
+	)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetMeterProvider(meterProvider)
+		global.SetLoggerProvider(loggerProvider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestIsolatedInstances(t *testing.T) {
+	restoreGlobals(t)
+
+	tracerProvider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	newIsolated := func(propagator string, sampler SamplerType) Telemetry {
+		tel, err := New(Config{
+			Service:       Service{Name: "test-service"},
+			Isolated:      true,
+			Propagators:   []string{propagator},
+			TracerOptions: TracerOptions{Sampler: &Sampler{Type: sampler}},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+		return tel
+	}
+	first := newIsolated("tracecontext", SamplerAlwaysOn)
+	second := newIsolated("b3multi", SamplerAlwaysOff)
+
+	assert.Same(t, tracerProvider, otel.GetTracerProvider(), "the globals are left untouched")
+	assert.Equal(t, propagation.TraceContext{}, otel.GetTextMapPropagator())
+
+	ctx, span := first.Trace().StartSpan(context.Background(), "first")
+	defer span.End()
+	assert.True(t, span.Span().IsRecording())
+
+	_, dropped := second.Trace().StartSpan(context.Background(), "second")
+	defer dropped.End()
+	assert.False(t, dropped.Span().IsRecording(), "each instance has its own provider")
+
+	firstCarrier, secondCarrier := make(map[string]string), make(map[string]string)
+	first.Inject(ctx, firstCarrier)
+	second.Inject(ctx, secondCarrier)
+	assert.Contains(t, firstCarrier, "traceparent")
+	assert.NotContains(t, firstCarrier, "x-b3-traceid")
+	assert.Contains(t, secondCarrier, "x-b3-traceid")
+	assert.NotContains(t, secondCarrier, "traceparent")
+
+	remote := second.Extract(context.Background(), secondCarrier)
+	assert.Equal(t, span.Span().SpanContext().TraceID(), second.Trace().SpanFromContext(remote).Span().SpanContext().TraceID())
+
+	second.SetGlobal()
+	carrier := make(map[string]string)
+	Inject(ctx, carrier)
+	assert.Equal(t, secondCarrier, carrier)
+	assert.NotSame(t, tracerProvider, otel.GetTracerProvider())
+}
+
+func TestDisabledTelemetryPropagatesNothing(t *testing.T) {
+	tel, err := New(Config{Disabled: true})
+	require.NoError(t, err)
+
+	ctx, span := newPropagatingTelemetry(t, "tracecontext").Trace().StartSpan(context.Background(), "span")
+	defer span.End()
+
+	carrier := make(map[string]string)
+	tel.Inject(ctx, carrier)
+	assert.Empty(t, carrier)
+}
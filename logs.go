@@ -2,13 +2,18 @@ package otelemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/log"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Log interface provides methods for logging operations.
@@ -20,33 +25,132 @@ type Log interface {
 	Info(ctx context.Context, msg string, kv ...log.KeyValue)
 	Warning(ctx context.Context, msg string, kv ...log.KeyValue)
 	Error(ctx context.Context, msg string, kv ...log.KeyValue)
+	// Fatal logs msg, flushes the logger, tracer and meter providers and
+	// exits with LoggerOptions.FatalExitCode.
 	Fatal(ctx context.Context, msg string, kv ...log.KeyValue)
+	// Panic logs msg, flushes the providers like Fatal and panics with msg.
+	Panic(ctx context.Context, msg string, kv ...log.KeyValue)
 }
 
+// fatalFlushTimeout bounds the flush of Fatal and Panic.
+const fatalFlushTimeout = 5 * time.Second
+
 // otellog is an implementation of the Log interface using OpenTelemetry.
 type otellog struct {
-	log log.Logger
+	log      log.Logger
+	clock    Clock
+	flush    func(ctx context.Context) error
+	exit     func(code int)
+	exitCode int
 }
 
-func newLoggerProvider(ctx context.Context, otelAgentAddr string, res *sdkresource.Resource, opts LoggerOptions) (*sdklog.LoggerProvider, error) {
-
-	exporter, err := otlploggrpc.New(ctx,
-		otlploggrpc.WithInsecure(),
-		otlploggrpc.WithEndpoint(otelAgentAddr),
-	)
+func newLoggerProvider(ctx context.Context, collector Collector, res *sdkresource.Resource, opts LoggerOptions) (*sdklog.LoggerProvider, error) {
+	exporter, err := newLogExporter(ctx, collector, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	provider := sdklog.NewLoggerProvider(
+	return sdklog.NewLoggerProvider(loggerProviderOpts(sdklog.NewBatchProcessor(exporter, opts.BatchProcessorOption...), res, opts)...), nil
+}
+
+// newMemoryLoggerProvider exports every record to memory as it is emitted.
+func newMemoryLoggerProvider(memory *Memory, res *sdkresource.Resource, opts LoggerOptions) (*sdklog.LoggerProvider, error) {
+	return sdklog.NewLoggerProvider(loggerProviderOpts(sdklog.NewSimpleProcessor(memory.logs), res, opts)...), nil
+}
+
+func loggerProviderOpts(processor sdklog.Processor, res *sdkresource.Resource, opts LoggerOptions) []sdklog.LoggerProviderOption {
+	return withDefaults([]sdklog.LoggerProviderOption{
 		sdklog.WithResource(res),
-		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
-	)
+		sdklog.WithProcessor(processor),
+	}, opts.ProviderOption...)
+}
 
-	return provider, nil
+func newLogExporter(ctx context.Context, collector Collector, opts LoggerOptions) (sdklog.Exporter, error) {
+	tlsCfg, err := newTLSConfig(collector.TLS, collector.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if collector.Protocol.isHTTP() {
+		return otlploghttp.New(ctx, logHTTPExporterOpts(collector, tlsCfg, opts.URLPath, opts.HTTPExporterOption...)...)
+	}
+
+	return otlploggrpc.New(ctx, logExporterOpts(collector, tlsCfg, opts.ExporterOption...)...)
 }
 
-func newStdoutLoggerProvider(res *sdkresource.Resource) (*sdklog.LoggerProvider, error) {
+func logHTTPExporterOpts(collector Collector, tlsCfg *tls.Config, urlPath string, opts ...otlploghttp.Option) []otlploghttp.Option {
+	options := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlploghttp.WithInsecure())
+	} else {
+		options = append(options, otlploghttp.WithTLSClientConfig(tlsCfg))
+	}
+
+	if urlPath != "" {
+		options = append(options, otlploghttp.WithURLPath(urlPath))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlploghttp.WithHeaders(collector.Headers))
+	}
+
+	if client := newHTTPClient(collector, tlsCfg, SignalLogs); client != nil {
+		options = append(options, otlploghttp.WithHTTPClient(client))
+	}
+
+	if collector.Timeout > 0 {
+		options = append(options, otlploghttp.WithTimeout(collector.Timeout))
+	}
+
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+	}
+
+	if collector.Retry != nil {
+		options = append(options, otlploghttp.WithRetry(otlploghttp.RetryConfig(collector.Retry.config())))
+	}
+
+	return withDefaults(options, opts...)
+}
+
+func logExporterOpts(collector Collector, tlsCfg *tls.Config, opts ...otlploggrpc.Option) []otlploggrpc.Option {
+	options := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlploggrpc.WithInsecure())
+	} else {
+		options = append(options, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlploggrpc.WithHeaders(collector.Headers))
+	}
+
+	if collector.Credentials != nil {
+		options = append(options, otlploggrpc.WithDialOption(grpc.WithPerRPCCredentials(newCredentialsPerRPC(collector.Credentials))))
+	}
+
+	if collector.Timeout > 0 {
+		options = append(options, otlploggrpc.WithTimeout(collector.Timeout))
+	}
+
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlploggrpc.WithCompressor(string(CompressionGzip)))
+	}
+
+	if collector.Retry != nil {
+		options = append(options, otlploggrpc.WithRetry(otlploggrpc.RetryConfig(collector.Retry.config())))
+	}
+
+	return withDefaults(options, opts...)
+}
+
+func newStdoutLoggerProvider(res *sdkresource.Resource, opts LoggerOptions) (*sdklog.LoggerProvider, error) {
 	exporter, err := stdoutlog.New()
 	if err != nil {
 		return nil, err
@@ -54,12 +158,7 @@ func newStdoutLoggerProvider(res *sdkresource.Resource) (*sdklog.LoggerProvider,
 	//stdoutlog.WithWriter(f),
 	//stdoutlog.WithPrettyPrint(),
 
-	provider := sdklog.NewLoggerProvider(
-		sdklog.WithResource(res),
-		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
-	)
-
-	return provider, nil
+	return sdklog.NewLoggerProvider(loggerProviderOpts(sdklog.NewBatchProcessor(exporter, opts.BatchProcessorOption...), res, opts)...), nil
 }
 
 const (
@@ -68,6 +167,7 @@ const (
 	Warn  = "WARN"
 	Error = "ERROR"
 	Fatal = "FATAL"
+	Panic = "PANIC"
 )
 
 func (l *otellog) Log() log.Logger {
@@ -75,36 +175,64 @@ func (l *otellog) Log() log.Logger {
 }
 
 func (l *otellog) Debug(ctx context.Context, msg string, kv ...log.KeyValue) {
-	record := getRecord(msg, log.SeverityDebug, Debug, kv...)
+	record := l.getRecord(msg, log.SeverityDebug, Debug, kv...)
 	l.log.Emit(ctx, record)
 }
 
 func (l *otellog) Info(ctx context.Context, msg string, kv ...log.KeyValue) {
-	record := getRecord(msg, log.SeverityInfo, Info, kv...)
+	record := l.getRecord(msg, log.SeverityInfo, Info, kv...)
 	l.log.Emit(ctx, record)
 }
 
 func (l *otellog) Warning(ctx context.Context, msg string, kv ...log.KeyValue) {
-	record := getRecord(msg, log.SeverityWarn, Warn, kv...)
+	record := l.getRecord(msg, log.SeverityWarn, Warn, kv...)
 	l.log.Emit(ctx, record)
 }
 
 func (l *otellog) Error(ctx context.Context, msg string, kv ...log.KeyValue) {
-	record := getRecord(msg, log.SeverityError, Error, kv...)
+	record := l.getRecord(msg, log.SeverityError, Error, kv...)
 	l.log.Emit(ctx, record)
 }
 
 func (l *otellog) Fatal(ctx context.Context, msg string, kv ...log.KeyValue) {
-	record := getRecord(msg, log.SeverityFatal, Fatal, kv...)
+	record := l.getRecord(msg, log.SeverityFatal, Fatal, kv...)
+	l.log.Emit(ctx, record)
+	l.flushProviders(ctx)
+	l.exit(l.exitCode)
+}
+
+func (l *otellog) Panic(ctx context.Context, msg string, kv ...log.KeyValue) {
+	record := l.getRecord(msg, log.SeverityFatal, Panic, kv...)
 	l.log.Emit(ctx, record)
+	l.flushProviders(ctx)
+	panic(msg)
 }
 
-func getRecord(msg string, severity log.Severity, sevName string, kv ...log.KeyValue) log.Record {
+// flushProviders exports what the providers buffered, before the process
+// may end. ctx being done does not cut the flush short.
+func (l *otellog) flushProviders(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fatalFlushTimeout)
+	defer cancel()
+
+	if err := l.flush(ctx); err != nil {
+		otel.Handle(err)
+	}
+}
+
+// getRecord stamps the record with the time of Config.Clock, which is also
+// the observed time when set.
+func (l *otellog) getRecord(msg string, severity log.Severity, sevName string, kv ...log.KeyValue) log.Record {
 	var record log.Record
 	record.SetBody(log.StringValue(msg))
 	record.SetSeverity(severity)
 	record.SetSeverityText(sevName)
-	record.SetTimestamp(time.Now())
+	if l.clock != nil {
+		now := l.clock.Now()
+		record.SetTimestamp(now)
+		record.SetObservedTimestamp(now)
+	} else {
+		record.SetTimestamp(time.Now())
+	}
 	record.AddAttributes(kv...)
 	return record
 }
@@ -0,0 +1,106 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newBufferingTelemetry exports to receiver on Shutdown or ForceFlush only.
+func newBufferingTelemetry(t *testing.T, receiver *httpReceiver, opts LoggerOptions) Telemetry {
+	opts.BatchProcessorOption = []sdklog.BatchProcessorOption{sdklog.WithExportInterval(time.Hour)}
+
+	tel, err := New(Config{
+		Service:     Service{Name: "test-service"},
+		Collector:   receiver.collector(t),
+		WithTraces:  true,
+		WithMetrics: true,
+		WithLogs:    true,
+		Isolated:    true,
+		TracerOptions: TracerOptions{
+			URLPath:                  "/custom/traces",
+			BatchSpanProcessorOption: []sdktrace.BatchSpanProcessorOption{sdktrace.WithBatchTimeout(time.Hour)},
+		},
+		MetricOptions: MetricOptions{PeriodicInterval: time.Hour},
+		LoggerOptions: opts,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	return tel
+}
+
+func TestFatalFlushesAndExits(t *testing.T) {
+	receiver := newHTTPReceiver(t)
+
+	var exitCodes []int
+	tel := newBufferingTelemetry(t, receiver, LoggerOptions{
+		FatalExitCode: 3,
+		ExitFunc:      func(code int) { exitCodes = append(exitCodes, code) },
+	})
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "before-fatal")
+	span.End()
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	tel.Log().Fatal(canceled, "cannot continue")
+
+	assert.Equal(t, []int{3}, exitCodes)
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.ElementsMatch(t, []string{"/custom/traces", "/v1/metrics", "/v1/logs"}, receiver.paths, "flushed before exiting")
+	assert.Equal(t, []string{"before-fatal"}, receiver.spans)
+}
+
+func TestPanicFlushesAndPanics(t *testing.T) {
+	var (
+		receiver = newHTTPReceiver(t)
+		recorder = &logRecorder{}
+		exited   bool
+	)
+	tel := newBufferingTelemetry(t, receiver, LoggerOptions{
+		ProviderOption: []sdklog.LoggerProviderOption{sdklog.WithProcessor(recorder)},
+		ExitFunc:       func(int) { exited = true },
+	})
+
+	assert.PanicsWithValue(t, "invariant broken", func() {
+		tel.Log().Panic(context.Background(), "invariant broken", LogAttribute("order", 42))
+	})
+	assert.False(t, exited)
+
+	receiver.mu.Lock()
+	assert.Contains(t, receiver.paths, "/v1/logs")
+	receiver.mu.Unlock()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.records, 1)
+	assert.Equal(t, log.SeverityFatal, recorder.records[0].Severity())
+	assert.Equal(t, Panic, recorder.records[0].SeverityText())
+}
+
+func TestFatalDefaultExitCode(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		var code int
+		tel, err := New(Config{
+			Service:       Service{Name: "test-service"},
+			Disabled:      disabled,
+			Isolated:      true,
+			LoggerOptions: LoggerOptions{ExitFunc: func(c int) { code = c }},
+		})
+		require.NoError(t, err)
+
+		tel.Log().Fatal(context.Background(), "fatal")
+		assert.Equal(t, 1, code, "disabled: %v", disabled)
+
+		require.NoError(t, tel.Shutdown(context.Background()))
+	}
+}
@@ -0,0 +1,124 @@
+package otelemetry
+
+import (
+	"context"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Memory holds the telemetry of the signals exported with ExporterMemory.
+// Spans and log records are kept as soon as they end or are emitted; metrics
+// are collected when read. Signals exported elsewhere read as empty.
+type Memory struct {
+	spans  *memorySpanExporter
+	reader *sdkmetric.ManualReader
+	logs   *memoryLogExporter
+
+	stamper *metricStamper
+}
+
+// Spans returns the ended spans.
+func (m *Memory) Spans() tracetest.SpanStubs {
+	if m.spans == nil {
+		return nil
+	}
+	return m.spans.GetSpans()
+}
+
+// Metrics collects the current metrics.
+func (m *Memory) Metrics(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+	if m.reader == nil {
+		return rm, nil
+	}
+
+	err := m.reader.Collect(ctx, &rm)
+	if err == nil && m.stamper != nil {
+		m.stamper.stamp(&rm)
+	}
+	return rm, err
+}
+
+// Logs returns the emitted log records.
+func (m *Memory) Logs() []sdklog.Record {
+	if m.logs == nil {
+		return nil
+	}
+	return m.logs.records()
+}
+
+// Reset drops the spans and log records kept so far.
+func (m *Memory) Reset() {
+	if m.spans != nil {
+		m.spans.Reset()
+	}
+	if m.logs != nil {
+		m.logs.reset()
+	}
+}
+
+// newMemory returns the Memory of the signals exported with ExporterMemory,
+// nil when there is none.
+func newMemory(traces, metrics, logs Exporter) *Memory {
+	if traces != ExporterMemory && metrics != ExporterMemory && logs != ExporterMemory {
+		return nil
+	}
+
+	m := &Memory{}
+	if traces == ExporterMemory {
+		m.spans = &memorySpanExporter{tracetest.NewInMemoryExporter()}
+	}
+	if metrics == ExporterMemory {
+		m.reader = sdkmetric.NewManualReader()
+	}
+	if logs == ExporterMemory {
+		m.logs = &memoryLogExporter{}
+	}
+
+	return m
+}
+
+// memorySpanExporter keeps the spans past Shutdown, which clears those of
+// tracetest.InMemoryExporter.
+type memorySpanExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (e *memorySpanExporter) Shutdown(context.Context) error { return nil }
+
+// memoryLogExporter is a log exporter keeping the records.
+type memoryLogExporter struct {
+	mu   sync.Mutex
+	logs []sdklog.Record
+}
+
+func (e *memoryLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, record := range records {
+		e.logs = append(e.logs, record.Clone())
+	}
+	return nil
+}
+
+func (e *memoryLogExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryLogExporter) ForceFlush(context.Context) error { return nil }
+
+func (e *memoryLogExporter) records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]sdklog.Record(nil), e.logs...)
+}
+
+func (e *memoryLogExporter) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.logs = nil
+}
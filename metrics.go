@@ -2,13 +2,17 @@ package otelemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Metric interface provides methods for creating and managing various types of metrics.
@@ -102,65 +106,139 @@ func (m *otelmetric) RegisterCallback(f metric.Callback, instruments ...metric.O
 	return m.metric.RegisterCallback(f, instruments...)
 }
 
-func newMeterProvider(ctx context.Context, otelAgentAddr string, res *sdkresource.Resource, opts MetricOptions) (*sdkmetric.MeterProvider, error) {
-	exporter, err := otlpmetricgrpc.New(ctx, meterExporterOpts(otelAgentAddr, opts.ExporterOptions...)...)
+// The meter providers stamp the metrics with stamper unless nil.
+func newMeterProvider(ctx context.Context, collector Collector, res *sdkresource.Resource, opts MetricOptions, stamper *metricStamper) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newMeterExporter(ctx, collector, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	provider := sdkmetric.NewMeterProvider(meterProviderOpts(exporter, func() time.Duration {
-		if opts.PeriodicInterval == 0 {
-			return 5 * time.Second
-		}
-		// Use the default if PeriodicInterval is not set
-		// This allows for a custom interval to be set in MetricOptions
-		return opts.PeriodicInterval
-	}(), res, opts.ProviderOptions...)...)
+	// OTLP exports every 5 seconds unless PeriodicInterval is set.
+	if opts.PeriodicInterval == 0 {
+		opts.PeriodicInterval = 5 * time.Second
+	}
 
-	return provider, nil
+	return sdkmetric.NewMeterProvider(meterProviderOpts(newPeriodicReader(exporter, opts, stamper), res, opts)...), nil
 }
 
-func newStdoutMeterProvider(res *sdkresource.Resource) (*sdkmetric.MeterProvider, error) {
+func newStdoutMeterProvider(res *sdkresource.Resource, opts MetricOptions, stamper *metricStamper) (*sdkmetric.MeterProvider, error) {
 	exporter, err := stdoutmetric.New()
 	if err != nil {
 		return nil, err
 	}
 
-	provider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
-	)
+	return sdkmetric.NewMeterProvider(meterProviderOpts(newPeriodicReader(exporter, opts, stamper), res, opts)...), nil
+}
+
+// newMemoryMeterProvider collects the metrics when Memory.Metrics is called.
+func newMemoryMeterProvider(memory *Memory, res *sdkresource.Resource, opts MetricOptions, stamper *metricStamper) (*sdkmetric.MeterProvider, error) {
+	memory.stamper = stamper
+	return sdkmetric.NewMeterProvider(meterProviderOpts(memory.reader, res, opts)...), nil
+}
+
+func newMeterExporter(ctx context.Context, collector Collector, opts MetricOptions) (sdkmetric.Exporter, error) {
+	tlsCfg, err := newTLSConfig(collector.TLS, collector.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if collector.Protocol.isHTTP() {
+		return otlpmetrichttp.New(ctx, meterHTTPExporterOpts(collector, tlsCfg, opts.URLPath, opts.HTTPExporterOptions...)...)
+	}
 
-	return provider, nil
+	return otlpmetricgrpc.New(ctx, meterExporterOpts(collector, tlsCfg, opts.ExporterOptions...)...)
 }
 
-func meterExporterOpts(otelAgentAddr string, opts ...otlpmetricgrpc.Option) []otlpmetricgrpc.Option {
+func meterHTTPExporterOpts(collector Collector, tlsCfg *tls.Config, urlPath string, opts ...otlpmetrichttp.Option) []otlpmetrichttp.Option {
+	options := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlpmetrichttp.WithInsecure())
+	} else {
+		options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+	}
+
+	if urlPath != "" {
+		options = append(options, otlpmetrichttp.WithURLPath(urlPath))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlpmetrichttp.WithHeaders(collector.Headers))
+	}
+
+	if client := newHTTPClient(collector, tlsCfg, SignalMetrics); client != nil {
+		options = append(options, otlpmetrichttp.WithHTTPClient(client))
+	}
+
+	if collector.Timeout > 0 {
+		options = append(options, otlpmetrichttp.WithTimeout(collector.Timeout))
+	}
+
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+
+	if collector.Retry != nil {
+		options = append(options, otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(collector.Retry.config())))
+	}
+
+	return withDefaults(options, opts...)
+}
+
+func meterExporterOpts(collector Collector, tlsCfg *tls.Config, opts ...otlpmetricgrpc.Option) []otlpmetricgrpc.Option {
 	options := []otlpmetricgrpc.Option{
-		otlpmetricgrpc.WithInsecure(),
-		otlpmetricgrpc.WithEndpoint(otelAgentAddr),
+		otlpmetricgrpc.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlpmetricgrpc.WithInsecure())
+	} else {
+		options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlpmetricgrpc.WithHeaders(collector.Headers))
 	}
 
-	if len(opts) == 0 {
-		return options
+	if collector.Credentials != nil {
+		options = append(options, otlpmetricgrpc.WithDialOption(grpc.WithPerRPCCredentials(newCredentialsPerRPC(collector.Credentials))))
 	}
 
-	return opts
+	if collector.Timeout > 0 {
+		options = append(options, otlpmetricgrpc.WithTimeout(collector.Timeout))
+	}
+
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlpmetricgrpc.WithCompressor(string(CompressionGzip)))
+	}
+
+	if collector.Retry != nil {
+		options = append(options, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(collector.Retry.config())))
+	}
+
+	return withDefaults(options, opts...)
 }
 
-func meterProviderOpts(exporter sdkmetric.Exporter, interval time.Duration, res *sdkresource.Resource, opts ...sdkmetric.Option) []sdkmetric.Option {
-	options := []sdkmetric.Option{
-		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(
-			sdkmetric.NewPeriodicReader(
-				exporter,
-				sdkmetric.WithInterval(interval), //2*time.Second
-			),
-		),
+// newPeriodicReader reads exporter periodically, every opts.PeriodicInterval
+// or the SDK default of one minute when it is not set.
+func newPeriodicReader(exporter sdkmetric.Exporter, opts MetricOptions, stamper *metricStamper) *sdkmetric.PeriodicReader {
+	if stamper != nil {
+		exporter = stampedExporter{Exporter: exporter, stamper: stamper}
 	}
 
-	if len(opts) > 0 {
-		options = append(options, opts...)
+	var readerOpts []sdkmetric.PeriodicReaderOption
+	if opts.PeriodicInterval > 0 {
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(opts.PeriodicInterval))
 	}
 
-	return options
+	return sdkmetric.NewPeriodicReader(exporter, readerOpts...)
+}
+
+func meterProviderOpts(reader sdkmetric.Reader, res *sdkresource.Resource, opts MetricOptions) []sdkmetric.Option {
+	return withDefaults([]sdkmetric.Option{
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(reader),
+	}, opts.ProviderOptions...)
 }
@@ -0,0 +1,367 @@
+package otelemetry
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type metricsService struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+}
+
+func (metricsService) Export(context.Context, *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+type logsService struct {
+	collectorlogs.UnimplementedLogsServiceServer
+}
+
+func (logsService) Export(context.Context, *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
+// grpcReceiver is a stand-in OTLP/gRPC receiver recording the metadata of
+// the last request of every method.
+type grpcReceiver struct {
+	mu       sync.Mutex
+	metadata map[string]metadata.MD
+	addr     net.Addr
+}
+
+func newGRPCReceiver(t *testing.T) *grpcReceiver {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	r := &grpcReceiver{metadata: make(map[string]metadata.MD), addr: lis.Addr()}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		r.mu.Lock()
+		r.metadata[info.FullMethod] = md
+		r.mu.Unlock()
+		return handler(ctx, req)
+	}))
+	collectortrace.RegisterTraceServiceServer(server, traceService{})
+	collectormetrics.RegisterMetricsServiceServer(server, metricsService{})
+	collectorlogs.RegisterLogsServiceServer(server, logsService{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	return r
+}
+
+func (r *grpcReceiver) collector(t *testing.T) Collector {
+	host, port, err := net.SplitHostPort(r.addr.String())
+	require.NoError(t, err)
+	return Collector{Host: host, Port: port, Protocol: ProtocolGRPC}
+}
+
+// gRPC methods of the OTLP services.
+const (
+	traceExportMethod   = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	metricsExportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	logsExportMethod    = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+)
+
+// header returns the value of the metadata key sent to method.
+func (r *grpcReceiver) header(method, key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if values := r.metadata[method].Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (r *httpReceiver) header(path, key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers[path].Get(key)
+}
+
+func (r *httpReceiver) received(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers[path] != nil
+}
+
+// logRecorder is a log processor keeping the emitted records.
+type logRecorder struct {
+	mu       sync.Mutex
+	records  []sdklog.Record
+	shutdown bool
+}
+
+func (r *logRecorder) OnEmit(_ context.Context, record *sdklog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record.Clone())
+	return nil
+}
+
+func (r *logRecorder) Shutdown(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown = true
+	return nil
+}
+
+func (r *logRecorder) ForceFlush(context.Context) error { return nil }
+
+// optionsRun holds the receivers and the telemetry of a TestOptionsReachProviders case.
+type optionsRun struct {
+	tel  Telemetry
+	span trace.Span
+	grpc *grpcReceiver
+	http *httpReceiver
+}
+
+func (r *optionsRun) shutdown(t *testing.T) {
+	require.NoError(t, r.tel.Shutdown(context.Background()))
+}
+
+func TestOptionsReachProviders(t *testing.T) {
+	var (
+		reader   = sdkmetric.NewManualReader()
+		recorder = &logRecorder{}
+	)
+
+	tests := []struct {
+		name      string
+		protocol  Protocol
+		configure func(cfg *Config)
+		check     func(t *testing.T, r *optionsRun)
+	}{
+		{
+			name:     "TracerOptions.ClientOption",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.ClientOption = []otlptracegrpc.Option{otlptracegrpc.WithHeaders(map[string]string{"x-option": "traces"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "traces", r.grpc.header(traceExportMethod, "x-option"))
+			},
+		},
+		{
+			name:     "TracerOptions.HTTPClientOption",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.HTTPClientOption = []otlptracehttp.Option{otlptracehttp.WithHeaders(map[string]string{"x-option": "traces"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "traces", r.http.header("/v1/traces", "x-option"))
+			},
+		},
+		{
+			name:     "TracerOptions.HTTPClientOption overrides URLPath",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.URLPath = "/ignored"
+				cfg.TracerOptions.HTTPClientOption = []otlptracehttp.Option{otlptracehttp.WithURLPath("/custom/traces")}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.True(t, r.http.received("/custom/traces"))
+				assert.False(t, r.http.received("/ignored"))
+			},
+		},
+		{
+			name:     "TracerOptions.ProviderOption",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.ProviderOption = []sdktrace.TracerProviderOption{sdktrace.WithSampler(sdktrace.NeverSample())}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.False(t, r.span.IsRecording())
+			},
+		},
+		{
+			name:     "TracerOptions.ProviderOption with stdout",
+			protocol: "",
+			configure: func(cfg *Config) {
+				cfg.WithTraces, cfg.WithMetrics, cfg.WithLogs = false, false, false
+				cfg.TracerOptions.ProviderOption = []sdktrace.TracerProviderOption{sdktrace.WithSampler(sdktrace.NeverSample())}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.False(t, r.span.IsRecording())
+			},
+		},
+		{
+			name:     "TracerOptions.BatchSpanProcessorOption",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.BatchSpanProcessorOption = []sdktrace.BatchSpanProcessorOption{sdktrace.WithBatchTimeout(10 * time.Millisecond)}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.Eventually(t, func() bool { return r.http.received("/v1/traces") }, time.Second, 10*time.Millisecond)
+			},
+		},
+		{
+			name:     "TracerOptions.TracerOption",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.TracerOptions.TracerOption = []trace.TracerOption{trace.WithInstrumentationVersion("1.2.3")}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.Equal(t, "1.2.3", r.span.(sdktrace.ReadOnlySpan).InstrumentationScope().Version)
+			},
+		},
+		{
+			name:     "MetricOptions.ExporterOptions",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.MetricOptions.ExporterOptions = []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(map[string]string{"x-option": "metrics"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "metrics", r.grpc.header(metricsExportMethod, "x-option"))
+			},
+		},
+		{
+			name:     "MetricOptions.HTTPExporterOptions",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.MetricOptions.HTTPExporterOptions = []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(map[string]string{"x-option": "metrics"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "metrics", r.http.header("/v1/metrics", "x-option"))
+			},
+		},
+		{
+			name:     "MetricOptions.ProviderOptions and MeterOptions",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.MetricOptions.ProviderOptions = []sdkmetric.Option{sdkmetric.WithReader(reader)}
+				cfg.MetricOptions.MeterOptions = []metric.MeterOption{metric.WithInstrumentationVersion("1.2.3")}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				var rm metricdata.ResourceMetrics
+				require.NoError(t, reader.Collect(context.Background(), &rm))
+				require.Len(t, rm.ScopeMetrics, 1)
+				assert.Equal(t, "1.2.3", rm.ScopeMetrics[0].Scope.Version)
+				assert.Equal(t, "requests", rm.ScopeMetrics[0].Metrics[0].Name)
+			},
+		},
+		{
+			name:     "MetricOptions.PeriodicInterval",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.MetricOptions.PeriodicInterval = 10 * time.Millisecond
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.Eventually(t, func() bool { return r.http.received("/v1/metrics") }, time.Second, 10*time.Millisecond)
+			},
+		},
+		{
+			name:     "LoggerOptions.ExporterOption",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.LoggerOptions.ExporterOption = []otlploggrpc.Option{otlploggrpc.WithHeaders(map[string]string{"x-option": "logs"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "logs", r.grpc.header(logsExportMethod, "x-option"))
+			},
+		},
+		{
+			name:     "LoggerOptions.HTTPExporterOption",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.LoggerOptions.HTTPExporterOption = []otlploghttp.Option{otlploghttp.WithHeaders(map[string]string{"x-option": "logs"})}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				r.shutdown(t)
+				assert.Equal(t, "logs", r.http.header("/v1/logs", "x-option"))
+			},
+		},
+		{
+			name:     "LoggerOptions.ProviderOption and LoggerOption",
+			protocol: ProtocolGRPC,
+			configure: func(cfg *Config) {
+				cfg.LoggerOptions.ProviderOption = []sdklog.LoggerProviderOption{sdklog.WithProcessor(recorder)}
+				cfg.LoggerOptions.LoggerOption = []log.LoggerOption{log.WithInstrumentationVersion("1.2.3")}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				recorder.mu.Lock()
+				defer recorder.mu.Unlock()
+				require.Len(t, recorder.records, 1)
+				assert.Equal(t, "1.2.3", recorder.records[0].InstrumentationScope().Version)
+			},
+		},
+		{
+			name:     "LoggerOptions.BatchProcessorOption",
+			protocol: ProtocolHTTPProtobuf,
+			configure: func(cfg *Config) {
+				cfg.LoggerOptions.BatchProcessorOption = []sdklog.BatchProcessorOption{sdklog.WithExportInterval(10 * time.Millisecond)}
+			},
+			check: func(t *testing.T, r *optionsRun) {
+				assert.Eventually(t, func() bool { return r.http.received("/v1/logs") }, time.Second, 10*time.Millisecond)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &optionsRun{grpc: newGRPCReceiver(t), http: newHTTPReceiver(t)}
+
+			cfg := Config{
+				Service:     Service{Name: "test-service"},
+				WithTraces:  true,
+				WithMetrics: true,
+				WithLogs:    true,
+			}
+			switch tt.protocol {
+			case ProtocolGRPC:
+				cfg.Collector = r.grpc.collector(t)
+			case ProtocolHTTPProtobuf:
+				cfg.Collector = r.http.collector(t)
+			}
+			tt.configure(&cfg)
+
+			tel, err := New(cfg)
+			require.NoError(t, err)
+			r.tel = tel
+			t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+			ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+			span.End()
+			r.span = span.Span()
+
+			counter, err := tel.Metric().Int64Counter("requests")
+			require.NoError(t, err)
+			counter.Add(ctx, 1)
+
+			tel.Log().Info(ctx, "message")
+
+			tt.check(t, r)
+		})
+	}
+}
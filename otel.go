@@ -2,18 +2,26 @@ package otelemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
+	lognoop "go.opentelemetry.io/otel/log/noop"
 	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 )
 
 // Telemetry implements the OpenTelemetry API.
@@ -33,10 +41,51 @@ type Telemetry interface {
 	// Metric returns the meter instance.
 	Metric() Metric
 
-	// Shutdown gracefully shuts down the telemetry providers.
+	// Shutdown flushes and shuts down the telemetry providers in parallel,
+	// within the deadline of ctx. It returns the failures of every signal
+	// joined; calls after the first one return the same result.
 	Shutdown(ctx context.Context) error
+
+	// ForceFlush exports everything buffered by the telemetry providers,
+	// without shutting them down.
+	ForceFlush(ctx context.Context) error
+
+	// OnShutdown registers a hook run by ShutdownOnSignal before the
+	// providers shut down. Hooks run in the order they were registered.
+	OnShutdown(hook ShutdownHook)
+
+	// ShutdownOnSignal blocks until one of signals (SIGINT and SIGTERM by
+	// default) is received or ctx is done. It then runs the shutdown hooks,
+	// emits a "service stopping" log record and shuts down the providers,
+	// all within grace (DefaultGracePeriod when zero), and returns the
+	// failures joined.
+	ShutdownOnSignal(ctx context.Context, grace time.Duration, signals ...os.Signal) error
+
+	// Propagator returns the context propagator built from Config.Propagators.
+	Propagator() propagation.TextMapPropagator
+
+	// Inject writes the span context and baggage of ctx into kv.
+	Inject(ctx context.Context, kv map[string]string)
+	// Extract returns ctx with the remote span context and baggage read from kv.
+	Extract(ctx context.Context, kv map[string]string) context.Context
+	// InjectHTTPHeaders writes the span context and baggage of ctx into headers.
+	InjectHTTPHeaders(ctx context.Context, headers http.Header)
+	// ExtractHTTPHeaders returns ctx with the remote span context and baggage
+	// read from headers.
+	ExtractHTTPHeaders(ctx context.Context, headers http.Header) context.Context
+
+	// SetGlobal installs the providers and propagator of the instance as the
+	// OpenTelemetry globals. New does so unless Config.Isolated is set.
+	SetGlobal()
+
+	// Memory returns the telemetry kept by the signals exported with
+	// ExporterMemory, nil when no signal is.
+	Memory() *Memory
 }
 
+// instrumentationName is the scope of the telemetry otelemetry reports about itself.
+const instrumentationName = "github.com/rorua/otelemetry"
+
 type telemetry struct {
 	tracerProvider *sdktrace.TracerProvider
 	meterProvider  *sdkmetric.MeterProvider
@@ -44,57 +93,145 @@ type telemetry struct {
 	tracer         trace.Tracer
 	meter          metric.Meter
 	logger         log.Logger
+	propagator     propagation.TextMapPropagator
+	memory         *Memory
+	clock          Clock
 	serviceName    string
+	exit           func(code int)
+	exitCode       int
+
+	mu    sync.Mutex
+	hooks []ShutdownHook
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// provider is implemented by the SDK tracer, meter and logger providers.
+type provider interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+type signalProvider struct {
+	signal string
+	provider
 }
 
 func (t *telemetry) Trace() Trace {
-	return &oteltrace{trace: t.tracer}
+	return &oteltrace{trace: t.tracer, clock: t.clock}
 }
 
 func (t *telemetry) Log() Log {
-	return &otellog{log: t.logger}
+	return &otellog{log: t.logger, clock: t.clock, flush: t.ForceFlush, exit: t.exit, exitCode: t.exitCode}
 }
 
 func (t *telemetry) Metric() Metric {
 	return &otelmetric{metric: t.meter}
 }
 
-func (t *telemetry) Shutdown(ctx context.Context) error {
-	cxt, cancel := context.WithTimeout(ctx, time.Second)
-	defer cancel()
+func (t *telemetry) Propagator() propagation.TextMapPropagator {
+	return t.propagator
+}
+
+func (t *telemetry) Memory() *Memory {
+	return t.memory
+}
 
-	// pushes any last exports to the receiver
+func (t *telemetry) SetGlobal() {
+	var (
+		tracerProvider trace.TracerProvider = tracenoop.NewTracerProvider()
+		meterProvider  metric.MeterProvider = metricnoop.NewMeterProvider()
+		loggerProvider log.LoggerProvider   = lognoop.NewLoggerProvider()
+	)
 	if t.tracerProvider != nil {
-		if err := t.tracerProvider.Shutdown(cxt); err != nil {
-			otel.Handle(err)
-		}
+		tracerProvider = t.tracerProvider
 	}
-
 	if t.meterProvider != nil {
-		if err := t.meterProvider.Shutdown(cxt); err != nil {
-			otel.Handle(err)
-		}
+		meterProvider = t.meterProvider
+	}
+	if t.loggerProvider != nil {
+		loggerProvider = t.loggerProvider
 	}
 
+	otel.SetTextMapPropagator(t.propagator)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	global.SetLoggerProvider(loggerProvider)
+}
+
+func (t *telemetry) Shutdown(ctx context.Context) error {
+	t.shutdownOnce.Do(func() {
+		// pushes any last exports to the receiver
+		t.shutdownErr = t.forEachProvider(ctx, "shutting down", provider.Shutdown)
+	})
+
+	return t.shutdownErr
+}
+
+func (t *telemetry) ForceFlush(ctx context.Context) error {
+	return t.forEachProvider(ctx, "flushing", provider.ForceFlush)
+}
+
+// forEachProvider calls fn on the providers in parallel and joins their
+// failures, naming the signal of each.
+func (t *telemetry) forEachProvider(ctx context.Context, action string, fn func(provider, context.Context) error) error {
+	var providers []signalProvider
+	if t.tracerProvider != nil {
+		providers = append(providers, signalProvider{SignalTraces, t.tracerProvider})
+	}
+	if t.meterProvider != nil {
+		providers = append(providers, signalProvider{SignalMetrics, t.meterProvider})
+	}
 	if t.loggerProvider != nil {
-		if err := t.loggerProvider.Shutdown(cxt); err != nil {
-			otel.Handle(err)
-		}
+		providers = append(providers, signalProvider{SignalLogs, t.loggerProvider})
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(providers))
+	)
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(p.provider, ctx); err != nil {
+				errs[i] = fmt.Errorf("otelemetry: %s %s: %w", action, p.signal, err)
+			}
+		}()
 	}
+	wg.Wait()
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // New creates a new Telemetry instance based on the provided configuration.
+//
+// The configuration is validated first; validation failures wrap ErrInvalidConfig.
+// If a signal's exporter or provider cannot be created, New returns an
+// *ExporterError and shuts down the providers created so far. The OpenTelemetry
+// globals are only set once every signal has been created successfully, and
+// not at all if cfg.Isolated is set.
+//
+// If cfg.Disabled is set, the returned Telemetry is a no-op and the
+// OpenTelemetry globals are left untouched.
 func New(cfg Config) (Telemetry, error) {
+	if cfg.Disabled {
+		otelemetry := newNoopTelemetry(cfg.Service.Name)
+		otelemetry.exit, otelemetry.exitCode = exitFunc(cfg.LoggerOptions)
+		return otelemetry, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
 	var (
-		ctx               = context.Background()
-		serviceName       = cfg.Service.Name
-		otelCollectorHost = cfg.Collector.Host
-		otelCollectorPort = cfg.Collector.Port
+		ctx         = context.Background()
+		serviceName = cfg.Service.Name
 
 		tracerProvider *sdktrace.TracerProvider
+		tailSampling   *tailSamplingProcessor
 		meterProvider  *sdkmetric.MeterProvider
 		loggerProvider *sdklog.LoggerProvider
 
@@ -104,54 +241,183 @@ func New(cfg Config) (Telemetry, error) {
 		}
 	)
 
-	// otel collector OTEL_COLLECTOR_HOST:OTEL_COLLECTOR_PORT_GRPC
-	otelAgentAddr := fmt.Sprintf("%s:%s", otelCollectorHost, otelCollectorPort)
-
 	// resource
 	res, err := newResource(ctx, cfg)
-	handleErr(err, "failed to create sdkresource")
+	if err != nil {
+		return nil, fmt.Errorf("otelemetry: creating resource: %w", err)
+	}
+
+	// propagator, already validated
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	// shared credentials are refreshed once for all signals
+	cfg.Collector.Credentials = cachedCredentials(cfg.Collector.Credentials)
+
+	traceExporter, metricExporter, logExporter := cfg.exporters()
+	memory := newMemory(traceExporter, metricExporter, logExporter)
 
 	// traces
-	if cfg.WithTraces {
-		tracerProvider, err = newTraceProvider(ctx, otelAgentAddr, res, cfg.TracerOptions)
-		handleErr(err, "failed to create the collector trace exporter or provider")
-	} else {
-		tracerProvider, err = newStdoutTraceProvider(res)
-		handleErr(err, "failed to create the collector trace exporter or provider")
+	switch traceExporter {
+	case ExporterOTLP:
+		tracerProvider, tailSampling, err = newTraceProvider(ctx, cfg.Collector.merge(cfg.TracerOptions.Collector), res, cfg.TracerOptions)
+	case ExporterStdout:
+		tracerProvider, tailSampling, err = newStdoutTraceProvider(res, cfg.TracerOptions)
+	case ExporterMemory:
+		tracerProvider, tailSampling, err = newMemoryTraceProvider(memory, res, cfg.TracerOptions)
+	}
+	if err != nil {
+		return nil, otelemetry.abort(ctx, &ExporterError{Signal: SignalTraces, Err: err})
 	}
 
-	// set global propagator to tracecontext (the default is no-op).
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
-	otel.SetTracerProvider(tracerProvider)
-	otelemetry.tracerProvider = tracerProvider
-	otelemetry.tracer = tracerProvider.Tracer(serviceName, cfg.TracerOptions.TracerOption...)
+	otelemetry.tracer = tracenoop.NewTracerProvider().Tracer(serviceName)
+	if tracerProvider != nil {
+		otelemetry.tracerProvider = tracerProvider
+		otelemetry.tracer = tracerProvider.Tracer(serviceName, cfg.TracerOptions.TracerOption...)
+	}
 
 	// metrics
-	if cfg.WithMetrics {
-		meterProvider, err = newMeterProvider(ctx, otelAgentAddr, res, cfg.MetricOptions)
-		handleErr(err, "failed to create the collector metric exporter or provider - grpc")
-	} else {
-		meterProvider, err = newStdoutMeterProvider(res)
-		handleErr(err, "failed to create the collector metric exporter or provider - stdout")
+	var stamper *metricStamper
+	if cfg.Clock != nil {
+		stamper = newMetricStamper(cfg.Clock)
 	}
 
-	otel.SetMeterProvider(meterProvider)
-	otelemetry.meterProvider = meterProvider
-	otelemetry.meter = meterProvider.Meter(serviceName, cfg.MetricOptions.MeterOptions...)
+	switch metricExporter {
+	case ExporterOTLP:
+		meterProvider, err = newMeterProvider(ctx, cfg.Collector.merge(cfg.MetricOptions.Collector), res, cfg.MetricOptions, stamper)
+	case ExporterStdout:
+		meterProvider, err = newStdoutMeterProvider(res, cfg.MetricOptions, stamper)
+	case ExporterMemory:
+		meterProvider, err = newMemoryMeterProvider(memory, res, cfg.MetricOptions, stamper)
+	}
+	if err != nil {
+		return nil, otelemetry.abort(ctx, &ExporterError{Signal: SignalMetrics, Err: err})
+	}
 
-	// logs - stdout or otlp
-	if cfg.WithLogs {
-		loggerProvider, err = newLoggerProvider(ctx, otelAgentAddr, res, cfg.LoggerOptions)
-		handleErr(err, "failed to create the logger provider")
-	} else {
-		loggerProvider, err = newStdoutLoggerProvider(res)
-		handleErr(err, "failed to create the stdout logger provider")
+	otelemetry.meter = metricnoop.NewMeterProvider().Meter(serviceName)
+	if meterProvider != nil {
+		otelemetry.meterProvider = meterProvider
+		otelemetry.meter = meterProvider.Meter(serviceName, cfg.MetricOptions.MeterOptions...)
+
+		if tailSampling != nil {
+			if err := tailSampling.registerMetrics(meterProvider.Meter(instrumentationName)); err != nil {
+				return nil, otelemetry.abort(ctx, fmt.Errorf("otelemetry: registering tail sampling metrics: %w", err))
+			}
+		}
 	}
 
-	// Set the logger provider globally
-	global.SetLoggerProvider(loggerProvider)
-	otelemetry.loggerProvider = loggerProvider
-	otelemetry.logger = loggerProvider.Logger(serviceName, cfg.LoggerOptions.LoggerOption...)
+	// logs
+	switch logExporter {
+	case ExporterOTLP:
+		loggerProvider, err = newLoggerProvider(ctx, cfg.Collector.merge(cfg.LoggerOptions.Collector), res, cfg.LoggerOptions)
+	case ExporterStdout:
+		loggerProvider, err = newStdoutLoggerProvider(res, cfg.LoggerOptions)
+	case ExporterMemory:
+		loggerProvider, err = newMemoryLoggerProvider(memory, res, cfg.LoggerOptions)
+	}
+	if err != nil {
+		return nil, otelemetry.abort(ctx, &ExporterError{Signal: SignalLogs, Err: err})
+	}
+
+	otelemetry.logger = lognoop.NewLoggerProvider().Logger(serviceName)
+	if loggerProvider != nil {
+		otelemetry.loggerProvider = loggerProvider
+		otelemetry.logger = loggerProvider.Logger(serviceName, cfg.LoggerOptions.LoggerOption...)
+	}
+
+	otelemetry.memory = memory
+	otelemetry.clock = cfg.Clock
+	otelemetry.propagator = propagator
+	otelemetry.exit, otelemetry.exitCode = exitFunc(cfg.LoggerOptions)
+
+	// set the globals, the default propagator is no-op.
+	if !cfg.Isolated {
+		otelemetry.SetGlobal()
+	}
 
 	return &otelemetry, nil
 }
+
+// endpoint returns the collector address as host:port.
+func (c Collector) endpoint() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// merge returns c with every field set in override replacing the shared value.
+// Headers are merged key by key.
+func (c Collector) merge(override *Collector) Collector {
+	if override == nil {
+		return c
+	}
+
+	if override.Host != "" {
+		c.Host = override.Host
+	}
+	if override.Port != "" {
+		c.Port = override.Port
+	}
+	if override.Protocol != "" {
+		c.Protocol = override.Protocol
+	}
+	if override.TLS != nil {
+		c.TLS = override.TLS
+	}
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(c.Headers)+len(override.Headers))
+		for k, v := range c.Headers {
+			headers[k] = v
+		}
+		for k, v := range override.Headers {
+			headers[k] = v
+		}
+		c.Headers = headers
+	}
+	if override.Credentials != nil {
+		c.Credentials = override.Credentials
+	}
+	c.ExportPolicy = c.ExportPolicy.merge(override.ExportPolicy)
+
+	return c
+}
+
+// exitFunc returns the exit function and code of Log.Fatal, with their defaults.
+func exitFunc(opts LoggerOptions) (func(code int), int) {
+	exit, code := opts.ExitFunc, opts.FatalExitCode
+	if exit == nil {
+		exit = os.Exit
+	}
+	if code == 0 {
+		code = 1
+	}
+
+	return exit, code
+}
+
+// abort shuts down the providers created so far and returns err,
+// joined with any shutdown failure.
+func (t *telemetry) abort(ctx context.Context, err error) error {
+	return errors.Join(err, t.Shutdown(ctx))
+}
+
+// NewNoop returns a Telemetry whose signals are no-ops and whose propagator
+// writes and reads nothing. It neither validates a configuration nor touches
+// the OpenTelemetry globals, which suits libraries and tests.
+func NewNoop() Telemetry {
+	return newNoopTelemetry("")
+}
+
+// newNoopTelemetry returns a Telemetry whose signals are backed by the
+// OpenTelemetry no-op implementations.
+func newNoopTelemetry(serviceName string) *telemetry {
+	return &telemetry{
+		tracer:      tracenoop.NewTracerProvider().Tracer(serviceName),
+		meter:       metricnoop.NewMeterProvider().Meter(serviceName),
+		logger:      lognoop.NewLoggerProvider().Logger(serviceName),
+		exit:        os.Exit,
+		exitCode:    1,
+		propagator:  propagation.NewCompositeTextMapPropagator(),
+		serviceName: serviceName,
+	}
+}
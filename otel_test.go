@@ -2,10 +2,12 @@ package otelemetry
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
@@ -37,11 +39,27 @@ func TestNewTelemetryWithInvalidResourceOptions(t *testing.T) {
 			Host: "localhost",
 			Port: "4317",
 		},
-		ResourceOptions: []resource.Option{resource.WithAttributes()},
+		ResourceOptions: []resource.Option{resource.WithDetectors(failingDetector{})},
 	}
 
 	_, err := New(cfg)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, errDetector)
+	assert.ErrorContains(t, err, "otelemetry: creating resource")
+
+	// an option adding no attributes is valid
+	cfg.ResourceOptions = []resource.Option{resource.WithAttributes()}
+	tel, err := New(cfg)
+	require.NoError(t, err)
+	assert.NoError(t, tel.Shutdown(context.Background()))
+}
+
+var errDetector = errors.New("detector failed")
+
+// failingDetector is a resource detector that always fails.
+type failingDetector struct{}
+
+func (failingDetector) Detect(context.Context) (*resource.Resource, error) {
+	return nil, errDetector
 }
 
 func TestShutdownTelemetryWithTimeout(t *testing.T) {
@@ -62,7 +80,72 @@ func TestShutdownTelemetryWithTimeout(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
 	defer cancel()
+	<-ctx.Done() // the deadline has passed when the providers shut down
 
 	err = tel.Shutdown(ctx)
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewTelemetryValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "bad port",
+			cfg: Config{
+				Service:    Service{Name: "test-service"},
+				Collector:  Collector{Host: "localhost", Port: "grpc"},
+				WithTraces: true,
+			},
+			err: `collector port "grpc" is not a valid port number`,
+		},
+		{
+			name: "missing host",
+			cfg: Config{
+				Service:     Service{Name: "test-service"},
+				Collector:   Collector{Port: "4317"},
+				WithMetrics: true,
+			},
+			err: "collector host is required",
+		},
+		{
+			name: "conflicting propagators",
+			cfg: Config{
+				Service:     Service{Name: "test-service"},
+				Propagators: []string{"none", "tracecontext"},
+			},
+			err: `propagator "none" cannot be combined`,
+		},
+		{
+			name: "negative interval",
+			cfg: Config{
+				Service:       Service{Name: "test-service"},
+				MetricOptions: MetricOptions{PeriodicInterval: -time.Second},
+			},
+			err: "must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tel, err := New(tt.cfg)
+			assert.Nil(t, tel)
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestExporterError(t *testing.T) {
+	cause := errors.New("dial failed")
+	err := error(&ExporterError{Signal: SignalLogs, Err: cause})
+
+	var exporterErr *ExporterError
+	assert.ErrorAs(t, err, &exporterErr)
+	assert.Equal(t, SignalLogs, exporterErr.Signal)
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, "otelemetry: creating logs exporter: dial failed")
 }
@@ -0,0 +1,587 @@
+package otelemetrytest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	lognoop "go.opentelemetry.io/otel/log/noop"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/rorua/otelemetry"
+)
+
+// The mocks implement the otelemetry interfaces.
+var (
+	_ otelemetry.Telemetry = (*Mock)(nil)
+	_ otelemetry.Trace     = (*MockTrace)(nil)
+	_ otelemetry.Span      = (*MockSpan)(nil)
+	_ otelemetry.Log       = (*MockLog)(nil)
+	_ otelemetry.Metric    = (*MockMetric)(nil)
+)
+
+// Mock is an otelemetry.Telemetry recording the calls made through it,
+// for services that take a Telemetry. Its fields are safe to read once the
+// code under test is done with it.
+type Mock struct {
+	MockTrace  *MockTrace
+	MockLog    *MockLog
+	MockMetric *MockMetric
+
+	// ShutdownErr and ForceFlushErr are returned by Shutdown and ForceFlush.
+	ShutdownErr   error
+	ForceFlushErr error
+
+	mu              sync.Mutex
+	hooks           []otelemetry.ShutdownHook
+	shutdownCalls   int
+	forceFlushCalls int
+	setGlobalCalls  int
+}
+
+// NewMock returns a Mock with empty recordings.
+func NewMock() *Mock {
+	return &Mock{
+		MockTrace:  &MockTrace{},
+		MockLog:    &MockLog{},
+		MockMetric: &MockMetric{},
+	}
+}
+
+func (m *Mock) Trace() otelemetry.Trace {
+	return m.MockTrace
+}
+
+func (m *Mock) Log() otelemetry.Log {
+	return m.MockLog
+}
+
+func (m *Mock) Metric() otelemetry.Metric {
+	return m.MockMetric
+}
+
+func (m *Mock) Shutdown(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shutdownCalls++
+	return m.ShutdownErr
+}
+
+func (m *Mock) ForceFlush(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.forceFlushCalls++
+	return m.ForceFlushErr
+}
+
+func (m *Mock) OnShutdown(hook otelemetry.ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook)
+}
+
+// ShutdownOnSignal does not wait for a signal: like otelemetry's, it runs
+// every shutdown hook in order, then calls Shutdown, and joins their errors.
+func (m *Mock) ShutdownOnSignal(ctx context.Context, _ time.Duration, _ ...os.Signal) error {
+	m.mu.Lock()
+	hooks := append([]otelemetry.ShutdownHook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("otelemetry: shutdown hook %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(append(errs, m.Shutdown(ctx))...)
+}
+
+// ShutdownCalls returns the number of calls to Shutdown.
+func (m *Mock) ShutdownCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.shutdownCalls
+}
+
+// ForceFlushCalls returns the number of calls to ForceFlush.
+func (m *Mock) ForceFlushCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.forceFlushCalls
+}
+
+// SetGlobalCalls returns the number of calls to SetGlobal.
+func (m *Mock) SetGlobalCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.setGlobalCalls
+}
+
+// Propagator returns a propagator writing and reading nothing.
+func (m *Mock) Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator()
+}
+
+func (m *Mock) Inject(context.Context, map[string]string) {}
+
+func (m *Mock) Extract(ctx context.Context, _ map[string]string) context.Context {
+	return ctx
+}
+
+func (m *Mock) InjectHTTPHeaders(context.Context, http.Header) {}
+
+func (m *Mock) ExtractHTTPHeaders(ctx context.Context, _ http.Header) context.Context {
+	return ctx
+}
+
+// SetGlobal only counts the call, the globals are left alone.
+func (m *Mock) SetGlobal() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setGlobalCalls++
+}
+
+func (m *Mock) Memory() *otelemetry.Memory {
+	return nil
+}
+
+// MockTrace is an otelemetry.Trace recording the spans started with it.
+type MockTrace struct {
+	mu     sync.Mutex
+	spans  []*MockSpan
+	nextID uint64
+}
+
+type mockSpanKey struct{}
+
+// Trace returns a no-op tracer.
+func (t *MockTrace) Trace() trace.Tracer {
+	return tracenoop.NewTracerProvider().Tracer("")
+}
+
+// StartSpan records a MockSpan, child of the MockSpan of ctx if any. Its
+// span context is valid, with IDs counting from 1, but it does not record.
+func (t *MockTrace) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, otelemetry.Span) {
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	t.mu.Unlock()
+
+	parent := mockSpanFromContext(ctx)
+	config := trace.NewSpanStartConfig(opts...)
+
+	sc := trace.SpanContextConfig{TraceFlags: trace.FlagsSampled}
+	binary.BigEndian.PutUint64(sc.SpanID[:], id)
+	if parentSC := trace.SpanContextFromContext(ctx); parentSC.IsValid() && !config.NewRoot() {
+		sc.TraceID = parentSC.TraceID()
+	} else {
+		binary.BigEndian.PutUint64(sc.TraceID[8:], id)
+		parent = nil
+	}
+
+	span := &MockSpan{
+		Name:       name,
+		Parent:     parent,
+		Kind:       config.SpanKind(),
+		span:       trace.SpanFromContext(trace.ContextWithSpanContext(ctx, trace.NewSpanContext(sc))),
+		attributes: config.Attributes(),
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	ctx = trace.ContextWithSpan(ctx, span.span)
+	return context.WithValue(ctx, mockSpanKey{}, span), span
+}
+
+// SpanFromContext returns the MockSpan of ctx, or one wrapping the span of
+// ctx when it was not started by StartSpan.
+func (t *MockTrace) SpanFromContext(ctx context.Context) otelemetry.Span {
+	if span := mockSpanFromContext(ctx); span != nil {
+		return span
+	}
+
+	return &MockSpan{span: trace.SpanFromContext(ctx)}
+}
+
+// mockSpanFromContext returns the MockSpan of ctx unless another span was
+// put in ctx since.
+func mockSpanFromContext(ctx context.Context) *MockSpan {
+	span, ok := ctx.Value(mockSpanKey{}).(*MockSpan)
+	if !ok || !span.span.SpanContext().Equal(trace.SpanContextFromContext(ctx)) {
+		return nil
+	}
+
+	return span
+}
+
+func (t *MockTrace) ContextWithSpan(ctx context.Context, span trace.Span) context.Context {
+	return trace.ContextWithSpan(ctx, span)
+}
+
+func (t *MockTrace) ContextWithRemoteSpanContext(ctx context.Context, span trace.Span) context.Context {
+	return trace.ContextWithRemoteSpanContext(ctx, span.SpanContext())
+}
+
+// Spans returns the spans started so far, in start order.
+func (t *MockTrace) Spans() []*MockSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*MockSpan(nil), t.spans...)
+}
+
+// SpanNames returns the names of the spans started so far, in start order.
+func (t *MockTrace) SpanNames() []string {
+	spans := t.Spans()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+
+	return names
+}
+
+// FindSpan returns the first span named name.
+func (t *MockTrace) FindSpan(name string) (*MockSpan, bool) {
+	for _, span := range t.Spans() {
+		if span.Name == name {
+			return span, true
+		}
+	}
+
+	return nil, false
+}
+
+// MockSpan is an otelemetry.Span recording the calls made on it. Its
+// methods are safe for concurrent use.
+type MockSpan struct {
+	Name string
+	// Parent is the span started before this one in the context, nil for a root.
+	Parent *MockSpan
+	Kind   trace.SpanKind
+
+	span trace.Span
+
+	mu         sync.Mutex
+	attributes []attribute.KeyValue
+	events     []MockEvent
+	errors     []error
+	ended      bool
+}
+
+// MockEvent is an event added to a MockSpan.
+type MockEvent struct {
+	Name string
+	// Err is the error of AddErrorEvent and RecordError.
+	Err        error
+	Attributes []attribute.KeyValue
+}
+
+// Span returns a non-recording span carrying the span context.
+func (s *MockSpan) Span() trace.Span {
+	return s.span
+}
+
+func (s *MockSpan) AddEvent(name string, kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, MockEvent{Name: name, Attributes: kv})
+}
+
+func (s *MockSpan) AddErrorEvent(name string, err error, kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, MockEvent{Name: name, Err: err, Attributes: kv})
+	s.errors = append(s.errors, err)
+}
+
+func (s *MockSpan) SetAttribute(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attributes = append(s.attributes, kv...)
+}
+
+func (s *MockSpan) End(...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ended = true
+}
+
+// RecordError records an "exception" event, as the SDK spans do.
+func (s *MockSpan) RecordError(err error, kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, MockEvent{Name: "exception", Err: err, Attributes: kv})
+	s.errors = append(s.errors, err)
+}
+
+// Attributes returns the start attributes followed by those set later.
+func (s *MockSpan) Attributes() []attribute.KeyValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]attribute.KeyValue(nil), s.attributes...)
+}
+
+// Events returns the events added so far, errors included.
+func (s *MockSpan) Events() []MockEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]MockEvent(nil), s.events...)
+}
+
+// Errors returns the errors of AddErrorEvent and RecordError so far.
+func (s *MockSpan) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errors...)
+}
+
+// Ended reports whether End was called.
+func (s *MockSpan) Ended() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ended
+}
+
+func (s *MockSpan) TraceID() string {
+	return s.span.SpanContext().TraceID().String()
+}
+
+func (s *MockSpan) SpanID() string {
+	return s.span.SpanContext().SpanID().String()
+}
+
+// MockLog is an otelemetry.Log recording the messages logged with it.
+// Fatal does not exit; Panic panics with the message like otelemetry.Log.
+type MockLog struct {
+	mu      sync.Mutex
+	records []MockRecord
+}
+
+// MockRecord is a message logged with a MockLog.
+type MockRecord struct {
+	Severity   log.Severity
+	Message    string
+	Attributes []log.KeyValue
+}
+
+// Log returns a no-op logger.
+func (l *MockLog) Log() log.Logger {
+	return lognoop.NewLoggerProvider().Logger("")
+}
+
+func (l *MockLog) Debug(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityDebug, msg, kv)
+}
+
+func (l *MockLog) Info(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityInfo, msg, kv)
+}
+
+func (l *MockLog) Warning(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityWarn, msg, kv)
+}
+
+func (l *MockLog) Error(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityError, msg, kv)
+}
+
+func (l *MockLog) Fatal(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityFatal, msg, kv)
+}
+
+func (l *MockLog) Panic(_ context.Context, msg string, kv ...log.KeyValue) {
+	l.record(log.SeverityFatal, msg, kv)
+	panic(msg)
+}
+
+func (l *MockLog) record(severity log.Severity, msg string, kv []log.KeyValue) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, MockRecord{Severity: severity, Message: msg, Attributes: kv})
+}
+
+// Records returns the messages logged so far.
+func (l *MockLog) Records() []MockRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]MockRecord(nil), l.records...)
+}
+
+// Messages returns the messages logged with severity so far.
+func (l *MockLog) Messages(severity log.Severity) []string {
+	var messages []string
+	for _, record := range l.Records() {
+		if record.Severity == severity {
+			messages = append(messages, record.Message)
+		}
+	}
+
+	return messages
+}
+
+// MockMetric is an otelemetry.Metric recording the instruments created with
+// it. The instruments are no-ops.
+type MockMetric struct {
+	mu          sync.Mutex
+	instruments []MockInstrument
+	callbacks   int
+}
+
+// MockInstrument is an instrument created with a MockMetric.
+type MockInstrument struct {
+	// Kind is the name of the Metric method, e.g. "Int64Counter".
+	Kind string
+	Name string
+}
+
+var noopMeter = metricnoop.NewMeterProvider().Meter("")
+
+// Metric returns a no-op meter.
+func (m *MockMetric) Metric() metric.Meter {
+	return noopMeter
+}
+
+func (m *MockMetric) Int64Counter(name string, options ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	m.record("Int64Counter", name)
+	return noopMeter.Int64Counter(name, options...)
+}
+
+func (m *MockMetric) Int64UpDownCounter(name string, options ...metric.Int64UpDownCounterOption) (metric.Int64UpDownCounter, error) {
+	m.record("Int64UpDownCounter", name)
+	return noopMeter.Int64UpDownCounter(name, options...)
+}
+
+func (m *MockMetric) Int64Histogram(name string, options ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	m.record("Int64Histogram", name)
+	return noopMeter.Int64Histogram(name, options...)
+}
+
+func (m *MockMetric) Int64Gauge(name string, options ...metric.Int64GaugeOption) (metric.Int64Gauge, error) {
+	m.record("Int64Gauge", name)
+	return noopMeter.Int64Gauge(name, options...)
+}
+
+func (m *MockMetric) Int64ObservableCounter(name string, options ...metric.Int64ObservableCounterOption) (metric.Int64ObservableCounter, error) {
+	m.record("Int64ObservableCounter", name)
+	return noopMeter.Int64ObservableCounter(name, options...)
+}
+
+func (m *MockMetric) Int64ObservableUpDownCounter(name string, options ...metric.Int64ObservableUpDownCounterOption) (metric.Int64ObservableUpDownCounter, error) {
+	m.record("Int64ObservableUpDownCounter", name)
+	return noopMeter.Int64ObservableUpDownCounter(name, options...)
+}
+
+func (m *MockMetric) Int64ObservableGauge(name string, options ...metric.Int64ObservableGaugeOption) (metric.Int64ObservableGauge, error) {
+	m.record("Int64ObservableGauge", name)
+	return noopMeter.Int64ObservableGauge(name, options...)
+}
+
+func (m *MockMetric) Float64Counter(name string, options ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	m.record("Float64Counter", name)
+	return noopMeter.Float64Counter(name, options...)
+}
+
+func (m *MockMetric) Float64UpDownCounter(name string, options ...metric.Float64UpDownCounterOption) (metric.Float64UpDownCounter, error) {
+	m.record("Float64UpDownCounter", name)
+	return noopMeter.Float64UpDownCounter(name, options...)
+}
+
+func (m *MockMetric) Float64Histogram(name string, options ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	m.record("Float64Histogram", name)
+	return noopMeter.Float64Histogram(name, options...)
+}
+
+func (m *MockMetric) Float64Gauge(name string, options ...metric.Float64GaugeOption) (metric.Float64Gauge, error) {
+	m.record("Float64Gauge", name)
+	return noopMeter.Float64Gauge(name, options...)
+}
+
+func (m *MockMetric) Float64ObservableCounter(name string, options ...metric.Float64ObservableCounterOption) (metric.Float64ObservableCounter, error) {
+	m.record("Float64ObservableCounter", name)
+	return noopMeter.Float64ObservableCounter(name, options...)
+}
+
+func (m *MockMetric) Float64ObservableUpDownCounter(name string, options ...metric.Float64ObservableUpDownCounterOption) (metric.Float64ObservableUpDownCounter, error) {
+	m.record("Float64ObservableUpDownCounter", name)
+	return noopMeter.Float64ObservableUpDownCounter(name, options...)
+}
+
+func (m *MockMetric) Float64ObservableGauge(name string, options ...metric.Float64ObservableGaugeOption) (metric.Float64ObservableGauge, error) {
+	m.record("Float64ObservableGauge", name)
+	return noopMeter.Float64ObservableGauge(name, options...)
+}
+
+func (m *MockMetric) RegisterCallback(f metric.Callback, instruments ...metric.Observable) (metric.Registration, error) {
+	m.mu.Lock()
+	m.callbacks++
+	m.mu.Unlock()
+
+	return noopMeter.RegisterCallback(f, instruments...)
+}
+
+func (m *MockMetric) record(kind, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.instruments = append(m.instruments, MockInstrument{Kind: kind, Name: name})
+}
+
+// Instruments returns the instruments created so far.
+func (m *MockMetric) Instruments() []MockInstrument {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]MockInstrument(nil), m.instruments...)
+}
+
+// InstrumentNames returns the names of the instruments created so far.
+func (m *MockMetric) InstrumentNames() []string {
+	instruments := m.Instruments()
+	names := make([]string, len(instruments))
+	for i, instrument := range instruments {
+		names[i] = instrument.Name
+	}
+
+	return names
+}
+
+// Callbacks returns the number of calls to RegisterCallback.
+func (m *MockMetric) Callbacks() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.callbacks
+}
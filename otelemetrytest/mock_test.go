@@ -0,0 +1,170 @@
+package otelemetrytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rorua/otelemetry"
+)
+
+var errNotFound = errors.New("not found")
+
+// getUser is a service method taking its Telemetry by injection.
+func getUser(ctx context.Context, tel otelemetry.Telemetry, id string) {
+	requests, _ := tel.Metric().Int64Counter("users.requests")
+	requests.Add(ctx, 1)
+
+	ctx, span := tel.Trace().StartSpan(ctx, "getUser", trace.WithAttributes(attribute.String("user.id", id)))
+	defer span.End()
+
+	_, lookup := tel.Trace().StartSpan(ctx, "cache.get")
+	lookup.AddErrorEvent("miss", errNotFound)
+	lookup.End()
+
+	tel.Log().Info(ctx, "user loaded", log.String("user.id", id))
+}
+
+func TestMock(t *testing.T) {
+	mock := NewMock()
+	getUser(context.Background(), mock, "42")
+
+	assert.Equal(t, []string{"getUser", "cache.get"}, mock.MockTrace.SpanNames())
+
+	span, ok := mock.MockTrace.FindSpan("getUser")
+	require.True(t, ok)
+	assert.True(t, span.Ended())
+	assert.Nil(t, span.Parent)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("user.id", "42")}, span.Attributes())
+
+	lookup, ok := mock.MockTrace.FindSpan("cache.get")
+	require.True(t, ok)
+	assert.Same(t, span, lookup.Parent)
+	assert.Equal(t, span.TraceID(), lookup.TraceID())
+	assert.NotEqual(t, span.SpanID(), lookup.SpanID())
+	assert.Equal(t, []error{errNotFound}, lookup.Errors())
+	require.Len(t, lookup.Events(), 1)
+	assert.Equal(t, "miss", lookup.Events()[0].Name)
+
+	assert.Equal(t, []string{"user loaded"}, mock.MockLog.Messages(log.SeverityInfo))
+	assert.Equal(t, []MockRecord{{Severity: log.SeverityInfo, Message: "user loaded", Attributes: []log.KeyValue{log.String("user.id", "42")}}}, mock.MockLog.Records())
+
+	assert.Equal(t, []MockInstrument{{Kind: "Int64Counter", Name: "users.requests"}}, mock.MockMetric.Instruments())
+	assert.Equal(t, []string{"users.requests"}, mock.MockMetric.InstrumentNames())
+}
+
+func TestMockSpanFromContext(t *testing.T) {
+	mock := NewMock()
+
+	ctx, span := mock.Trace().StartSpan(context.Background(), "span")
+	assert.Same(t, span, mock.Trace().SpanFromContext(ctx))
+
+	other := trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{9},
+		SpanID:  trace.SpanID{9},
+	})))
+	ctx = mock.Trace().ContextWithSpan(ctx, other)
+	assert.NotSame(t, span, mock.Trace().SpanFromContext(ctx))
+
+	_, child := mock.Trace().StartSpan(ctx, "child")
+	assert.Nil(t, child.(*MockSpan).Parent, "the parent is not a mock span")
+	assert.Equal(t, other.SpanContext().TraceID().String(), child.TraceID())
+}
+
+func TestMockSpanConcurrent(t *testing.T) {
+	mock := NewMock()
+	_, span := mock.Trace().StartSpan(context.Background(), "span")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			span.SetAttribute(attribute.Int("i", i))
+			span.AddEvent("event")
+			span.RecordError(errNotFound)
+		}()
+	}
+	wg.Wait()
+	span.End()
+
+	recorded := span.(*MockSpan)
+	assert.Len(t, recorded.Attributes(), 10)
+	assert.Len(t, recorded.Events(), 20)
+	assert.Len(t, recorded.Errors(), 10)
+	assert.True(t, recorded.Ended())
+
+	// the accessors return copies
+	recorded.Errors()[0] = nil
+	assert.Equal(t, errNotFound, recorded.Errors()[0])
+}
+
+func TestMockSpanIDsDoNotWrap(t *testing.T) {
+	mock := NewMock()
+	mock.MockTrace.nextID = 1<<16 - 1
+
+	ctx, root := mock.Trace().StartSpan(context.Background(), "root")
+	_, child := mock.Trace().StartSpan(ctx, "child")
+
+	assert.True(t, root.Span().SpanContext().IsValid())
+	assert.Equal(t, "0000000000010000", root.SpanID())
+	assert.Equal(t, "00000000000000000000000000010000", root.TraceID())
+	assert.Equal(t, "0000000000010001", child.SpanID())
+	assert.Same(t, root, child.(*MockSpan).Parent)
+}
+
+func TestMockShutdownOnSignal(t *testing.T) {
+	mock := NewMock()
+	var calls []string
+	mock.OnShutdown(func(context.Context) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	mock.OnShutdown(func(context.Context) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	mock.ShutdownErr = errors.New("shutdown failed")
+
+	err := mock.ShutdownOnSignal(context.Background(), 0)
+	assert.EqualError(t, err, "shutdown failed")
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, 1, mock.ShutdownCalls())
+}
+
+func TestMockShutdownOnSignalRunsEveryHook(t *testing.T) {
+	mock := NewMock()
+	hookErr := errors.New("drain failed")
+	var calls []string
+	mock.OnShutdown(func(context.Context) error {
+		calls = append(calls, "first")
+		return hookErr
+	})
+	mock.OnShutdown(func(context.Context) error {
+		calls = append(calls, "second")
+		return nil
+	})
+	mock.ShutdownErr = errors.New("shutdown failed")
+
+	err := mock.ShutdownOnSignal(context.Background(), 0)
+	assert.ErrorIs(t, err, hookErr)
+	assert.ErrorIs(t, err, mock.ShutdownErr)
+	assert.ErrorContains(t, err, "shutdown hook 0")
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, 1, mock.ShutdownCalls())
+}
+
+func TestMockLogPanic(t *testing.T) {
+	mock := NewMock()
+
+	mock.Log().Fatal(context.Background(), "fatal")
+	assert.PanicsWithValue(t, "panic", func() { mock.Log().Panic(context.Background(), "panic") })
+	assert.Equal(t, []string{"fatal", "panic"}, mock.MockLog.Messages(log.SeverityFatal))
+}
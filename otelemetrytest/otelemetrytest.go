@@ -0,0 +1,263 @@
+// Package otelemetrytest builds a Telemetry that records its spans, metrics
+// and log records in memory, with helpers to query and assert on them.
+//
+// Example:
+// tel := otelemetrytest.New(t, otelemetry.Config{})
+// handler(tel).ServeHTTP(w, r)
+// tel.AssertSpan("GET /users", attribute.Int("http.status_code", 200))
+// tel.AssertInt64Value("requests", 1, attribute.String("route", "/users"))
+package otelemetrytest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/rorua/otelemetry"
+)
+
+// Telemetry is an otelemetry.Telemetry exporting every signal to memory.
+type Telemetry struct {
+	otelemetry.Telemetry
+	tb testing.TB
+}
+
+// New returns an isolated Telemetry built from cfg with every signal exported
+// to memory. The service name defaults to "test". It fails tb when cfg is
+// invalid and shuts the Telemetry down when the test ends. Call SetGlobal
+// for code that reads the OpenTelemetry globals.
+func New(tb testing.TB, cfg otelemetry.Config) *Telemetry {
+	tb.Helper()
+
+	if cfg.Service.Name == "" {
+		cfg.Service.Name = "test"
+	}
+	cfg.Isolated = true
+	cfg.TracerOptions.Exporter = otelemetry.ExporterMemory
+	cfg.MetricOptions.Exporter = otelemetry.ExporterMemory
+	cfg.LoggerOptions.Exporter = otelemetry.ExporterMemory
+
+	tel, err := otelemetry.New(cfg)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	return &Telemetry{Telemetry: tel, tb: tb}
+}
+
+// Reset drops the spans and log records recorded so far.
+func (t *Telemetry) Reset() {
+	t.Memory().Reset()
+}
+
+// Spans returns the ended spans.
+func (t *Telemetry) Spans() tracetest.SpanStubs {
+	return t.Memory().Spans()
+}
+
+// FindSpans returns the ended spans named name, any name when empty, that
+// have every attribute of attrs.
+func (t *Telemetry) FindSpans(name string, attrs ...attribute.KeyValue) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, span := range t.Spans() {
+		if (name == "" || span.Name == name) && hasAttributes(span.Attributes, attrs) {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+// FindSpan returns the first span FindSpans returns.
+func (t *Telemetry) FindSpan(name string, attrs ...attribute.KeyValue) (tracetest.SpanStub, bool) {
+	spans := t.FindSpans(name, attrs...)
+	if len(spans) == 0 {
+		return tracetest.SpanStub{}, false
+	}
+
+	return spans[0], true
+}
+
+// Metrics collects the current metrics. It fails the test when the
+// collection fails.
+func (t *Telemetry) Metrics() metricdata.ResourceMetrics {
+	t.tb.Helper()
+
+	rm, err := t.Memory().Metrics(context.Background())
+	require.NoError(t.tb, err)
+
+	return rm
+}
+
+// FindMetric returns the instrument named name, whatever its scope.
+func (t *Telemetry) FindMetric(name string) (metricdata.Metrics, bool) {
+	t.tb.Helper()
+
+	for _, sm := range t.Metrics().ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+
+	return metricdata.Metrics{}, false
+}
+
+// DataPoint returns the data point of the counter, up-down counter or gauge
+// named name whose attribute set is exactly attrs.
+func DataPoint[N int64 | float64](t *Telemetry, name string, attrs ...attribute.KeyValue) (metricdata.DataPoint[N], bool) {
+	t.tb.Helper()
+
+	m, ok := t.FindMetric(name)
+	if !ok {
+		return metricdata.DataPoint[N]{}, false
+	}
+
+	var points []metricdata.DataPoint[N]
+	switch data := m.Data.(type) {
+	case metricdata.Sum[N]:
+		points = data.DataPoints
+	case metricdata.Gauge[N]:
+		points = data.DataPoints
+	}
+
+	return findPoint(points, attrs, func(p metricdata.DataPoint[N]) attribute.Set { return p.Attributes })
+}
+
+// HistogramDataPoint returns the data point of the histogram named name whose
+// attribute set is exactly attrs.
+func HistogramDataPoint[N int64 | float64](t *Telemetry, name string, attrs ...attribute.KeyValue) (metricdata.HistogramDataPoint[N], bool) {
+	t.tb.Helper()
+
+	m, ok := t.FindMetric(name)
+	if !ok {
+		return metricdata.HistogramDataPoint[N]{}, false
+	}
+
+	data, _ := m.Data.(metricdata.Histogram[N])
+	return findPoint(data.DataPoints, attrs, func(p metricdata.HistogramDataPoint[N]) attribute.Set { return p.Attributes })
+}
+
+// Logs returns the emitted log records.
+func (t *Telemetry) Logs() []sdklog.Record {
+	return t.Memory().Logs()
+}
+
+// FindLogs returns the log records emitted with severity.
+func (t *Telemetry) FindLogs(severity log.Severity) []sdklog.Record {
+	var records []sdklog.Record
+	for _, record := range t.Logs() {
+		if record.Severity() == severity {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// AssertSpan asserts that a span named name with every attribute of attrs
+// has ended.
+func (t *Telemetry) AssertSpan(name string, attrs ...attribute.KeyValue) bool {
+	t.tb.Helper()
+
+	if _, ok := t.FindSpan(name, attrs...); ok {
+		return true
+	}
+
+	return assert.Fail(t.tb, fmt.Sprintf("no span %q with attributes %v", name, attrs), "ended spans: %s", spanNames(t.Spans()))
+}
+
+// AssertNoSpan asserts that no span named name with every attribute of attrs
+// has ended.
+func (t *Telemetry) AssertNoSpan(name string, attrs ...attribute.KeyValue) bool {
+	t.tb.Helper()
+
+	spans := t.FindSpans(name, attrs...)
+	if len(spans) == 0 {
+		return true
+	}
+
+	return assert.Fail(t.tb, fmt.Sprintf("unexpected span %q with attributes %v", name, attrs), "matching spans: %d", len(spans))
+}
+
+// AssertInt64Value asserts the value of the int64 counter, up-down counter or
+// gauge named name for the attribute set attrs.
+func (t *Telemetry) AssertInt64Value(name string, want int64, attrs ...attribute.KeyValue) bool {
+	t.tb.Helper()
+	return assertValue(t, name, want, attrs)
+}
+
+// AssertFloat64Value asserts the value of the float64 counter, up-down
+// counter or gauge named name for the attribute set attrs.
+func (t *Telemetry) AssertFloat64Value(name string, want float64, attrs ...attribute.KeyValue) bool {
+	t.tb.Helper()
+	return assertValue(t, name, want, attrs)
+}
+
+// AssertLog asserts that a record with severity and body was emitted.
+func (t *Telemetry) AssertLog(severity log.Severity, body string) bool {
+	t.tb.Helper()
+
+	records := t.FindLogs(severity)
+	bodies := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Body().AsString() == body {
+			return true
+		}
+		bodies = append(bodies, record.Body().AsString())
+	}
+
+	return assert.Fail(t.tb, fmt.Sprintf("no %s log record %q", severity, body), "records at that severity: %q", bodies)
+}
+
+func assertValue[N int64 | float64](t *Telemetry, name string, want N, attrs []attribute.KeyValue) bool {
+	t.tb.Helper()
+
+	point, ok := DataPoint[N](t, name, attrs...)
+	if !ok {
+		return assert.Fail(t.tb, fmt.Sprintf("no data point of %q with attributes %v", name, attrs))
+	}
+
+	return assert.Equal(t.tb, want, point.Value, "value of %q with attributes %v", name, attrs)
+}
+
+func findPoint[P any](points []P, attrs []attribute.KeyValue, attributes func(P) attribute.Set) (P, bool) {
+	set := attribute.NewSet(attrs...)
+	for _, p := range points {
+		if s := attributes(p); s.Equals(&set) {
+			return p, true
+		}
+	}
+
+	var zero P
+	return zero, false
+}
+
+func hasAttributes(have, want []attribute.KeyValue) bool {
+	set := attribute.NewSet(have...)
+	for _, kv := range want {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func spanNames(spans tracetest.SpanStubs) string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+
+	return strings.Join(names, ", ")
+}
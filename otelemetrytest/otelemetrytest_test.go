@@ -0,0 +1,132 @@
+package otelemetrytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/rorua/otelemetry"
+)
+
+// failures records the failed assertions instead of failing the test.
+type failures struct {
+	testing.TB
+	messages []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...interface{}) {
+	f.messages = append(f.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSpans(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+
+	ctx, parent := tel.Trace().StartSpan(context.Background(), "GET /users")
+	parent.Span().SetAttributes(attribute.Int("http.status_code", 200))
+	_, child := tel.Trace().StartSpan(ctx, "db.query")
+	child.End()
+	parent.End()
+
+	require.Len(t, tel.Spans(), 2)
+	assert.Len(t, tel.FindSpans("", attribute.Int("http.status_code", 200)), 1)
+	assert.Empty(t, tel.FindSpans("GET /users", attribute.Int("http.status_code", 500)))
+
+	span, ok := tel.FindSpan("db.query")
+	require.True(t, ok)
+	assert.Equal(t, parent.Span().SpanContext().SpanID(), span.Parent.SpanID())
+
+	tel.AssertSpan("GET /users", attribute.Int("http.status_code", 200))
+	tel.AssertNoSpan("GET /orders")
+
+	tel.Reset()
+	assert.Empty(t, tel.Spans())
+}
+
+func TestMetrics(t *testing.T) {
+	tel := New(t, otelemetry.Config{Service: otelemetry.Service{Name: "users"}})
+	ctx := context.Background()
+
+	requests, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	requests.Add(ctx, 2, metric.WithAttributes(attribute.String("route", "/users")))
+	requests.Add(ctx, 1, metric.WithAttributes(attribute.String("route", "/orders")))
+
+	latency, err := tel.Metric().Float64Histogram("latency")
+	require.NoError(t, err)
+	latency.Record(ctx, 0.25)
+	latency.Record(ctx, 0.75)
+
+	point, ok := DataPoint[int64](tel, "requests", attribute.String("route", "/orders"))
+	require.True(t, ok)
+	assert.Equal(t, int64(1), point.Value)
+
+	_, ok = DataPoint[int64](tel, "requests")
+	assert.False(t, ok, "the attribute set must match exactly")
+	_, ok = DataPoint[float64](tel, "requests", attribute.String("route", "/orders"))
+	assert.False(t, ok, "requests is an int64 counter")
+
+	histogram, ok := HistogramDataPoint[float64](tel, "latency")
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), histogram.Count)
+	assert.Equal(t, 1.0, histogram.Sum)
+
+	tel.AssertInt64Value("requests", 2, attribute.String("route", "/users"))
+}
+
+func TestLogs(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	ctx := context.Background()
+
+	tel.Log().Info(ctx, "started")
+	tel.Log().Error(ctx, "failed", log.Int("attempt", 1))
+	tel.Log().Error(ctx, "failed again")
+
+	assert.Len(t, tel.Logs(), 3)
+	assert.Len(t, tel.FindLogs(log.SeverityError), 2)
+	assert.Empty(t, tel.FindLogs(log.SeverityWarn))
+
+	tel.AssertLog(log.SeverityInfo, "started")
+}
+
+func TestAssertionsReportFailures(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	_, span := tel.Trace().StartSpan(context.Background(), "GET /users")
+	span.End()
+
+	f := &failures{TB: t}
+	tel.tb = f
+
+	assert.False(t, tel.AssertSpan("GET /orders"))
+	assert.False(t, tel.AssertNoSpan("GET /users"))
+	assert.False(t, tel.AssertInt64Value("requests", 1))
+	assert.False(t, tel.AssertLog(log.SeverityInfo, "started"))
+
+	require.Len(t, f.messages, 4)
+	assert.Contains(t, f.messages[0], `no span "GET /orders"`)
+	assert.Contains(t, f.messages[0], "ended spans: GET /users")
+	assert.Contains(t, f.messages[2], `no data point of "requests"`)
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	tel := New(t, otelemetry.Config{Clock: clock})
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	clock.Advance(time.Second)
+	span.End()
+
+	stub, ok := tel.FindSpan("span")
+	require.True(t, ok)
+	assert.Equal(t, start, stub.StartTime)
+	assert.Equal(t, start.Add(time.Second), stub.EndTime)
+}
@@ -0,0 +1,397 @@
+package otelemetrytest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // gzip compressed exports
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rorua/otelemetry"
+)
+
+// Receiver is an in-process OTLP receiver of traces, metrics and logs,
+// listening on a random localhost port with gRPC, HTTP/protobuf or
+// HTTP/JSON.
+//
+// Example:
+// receiver := otelemetrytest.NewReceiver(t, otelemetry.ProtocolGRPC)
+// tel, err := otelemetry.New(otelemetry.Config{Collector: receiver.Collector(), WithTraces: true})
+// ...
+// tel.Shutdown(ctx)
+// spans := receiver.Spans()
+type Receiver struct {
+	protocol otelemetry.Protocol
+	addr     string
+
+	mu       sync.Mutex
+	requests int
+	traces   []*collectortrace.ExportTraceServiceRequest
+	metrics  []*collectormetrics.ExportMetricsServiceRequest
+	logs     []*collectorlogs.ExportLogsServiceRequest
+	failures int
+	code     codes.Code
+	delay    time.Duration
+}
+
+// NewReceiver starts a Receiver speaking protocol, ProtocolGRPC when empty,
+// and stops it when the test ends.
+func NewReceiver(tb testing.TB, protocol otelemetry.Protocol) *Receiver {
+	tb.Helper()
+
+	if protocol == "" {
+		protocol = otelemetry.ProtocolGRPC
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	r := &Receiver{protocol: protocol, addr: listener.Addr().String()}
+	switch protocol {
+	case otelemetry.ProtocolGRPC:
+		server := grpc.NewServer()
+		collectortrace.RegisterTraceServiceServer(server, traceService{r: r})
+		collectormetrics.RegisterMetricsServiceServer(server, metricsService{r: r})
+		collectorlogs.RegisterLogsServiceServer(server, logsService{r: r})
+		go func() { _ = server.Serve(listener) }()
+		tb.Cleanup(server.Stop)
+	case otelemetry.ProtocolHTTPProtobuf, otelemetry.ProtocolHTTPJSON:
+		server := &http.Server{Handler: r.handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() { _ = server.Serve(listener) }()
+		tb.Cleanup(func() { _ = server.Close() })
+	default:
+		_ = listener.Close()
+		require.FailNow(tb, "unsupported receiver protocol "+string(protocol))
+	}
+
+	return r
+}
+
+// Collector returns the collector exporting to the receiver.
+func (r *Receiver) Collector() otelemetry.Collector {
+	host, port, _ := net.SplitHostPort(r.addr)
+	return otelemetry.Collector{Host: host, Port: port, Protocol: r.protocol}
+}
+
+// Fail makes the next n requests fail with code, or its HTTP status for
+// OTLP/HTTP, and every request when n is negative. Fail(0, codes.OK)
+// restores successful exports.
+func (r *Receiver) Fail(n int, code codes.Code) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures, r.code = n, code
+}
+
+// SetDelay holds every following request for d before answering.
+func (r *Receiver) SetDelay(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delay = d
+}
+
+// Requests returns the number of requests received, failed ones included.
+func (r *Receiver) Requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.requests
+}
+
+// TraceRequests returns the trace export requests accepted so far.
+func (r *Receiver) TraceRequests() []*collectortrace.ExportTraceServiceRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*collectortrace.ExportTraceServiceRequest(nil), r.traces...)
+}
+
+// MetricRequests returns the metric export requests accepted so far.
+func (r *Receiver) MetricRequests() []*collectormetrics.ExportMetricsServiceRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*collectormetrics.ExportMetricsServiceRequest(nil), r.metrics...)
+}
+
+// LogRequests returns the log export requests accepted so far.
+func (r *Receiver) LogRequests() []*collectorlogs.ExportLogsServiceRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*collectorlogs.ExportLogsServiceRequest(nil), r.logs...)
+}
+
+// Spans returns the spans of every accepted trace request.
+func (r *Receiver) Spans() []*tracepb.Span {
+	var spans []*tracepb.Span
+	for _, req := range r.TraceRequests() {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+
+	return spans
+}
+
+// Metrics returns the metrics of every accepted metric request.
+func (r *Receiver) Metrics() []*metricspb.Metric {
+	var metrics []*metricspb.Metric
+	for _, req := range r.MetricRequests() {
+		for _, rm := range req.ResourceMetrics {
+			for _, sm := range rm.ScopeMetrics {
+				metrics = append(metrics, sm.Metrics...)
+			}
+		}
+	}
+
+	return metrics
+}
+
+// LogRecords returns the log records of every accepted log request.
+func (r *Receiver) LogRecords() []*logspb.LogRecord {
+	var records []*logspb.LogRecord
+	for _, req := range r.LogRequests() {
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				records = append(records, sl.LogRecords...)
+			}
+		}
+	}
+
+	return records
+}
+
+// receive counts a request and waits for the delay, then calls record
+// unless the receiver is set to fail.
+func (r *Receiver) receive(ctx context.Context, record func()) error {
+	r.mu.Lock()
+	r.requests++
+	delay := r.delay
+	code := codes.OK
+	if r.failures != 0 {
+		code = r.code
+		if r.failures > 0 {
+			r.failures--
+		}
+	}
+	r.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	if code != codes.OK {
+		return status.Error(code, "otelemetrytest: receiver set to fail")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record()
+	return nil
+}
+
+type traceService struct {
+	collectortrace.UnimplementedTraceServiceServer
+	r *Receiver
+}
+
+func (s traceService) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	if err := s.r.receive(ctx, func() { s.r.traces = append(s.r.traces, req) }); err != nil {
+		return nil, err
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+type metricsService struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	r *Receiver
+}
+
+func (s metricsService) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	if err := s.r.receive(ctx, func() { s.r.metrics = append(s.r.metrics, req) }); err != nil {
+		return nil, err
+	}
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+type logsService struct {
+	collectorlogs.UnimplementedLogsServiceServer
+	r *Receiver
+}
+
+func (s logsService) Export(ctx context.Context, req *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	if err := s.r.receive(ctx, func() { s.r.logs = append(s.r.logs, req) }); err != nil {
+		return nil, err
+	}
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
+// handler serves the OTLP/HTTP export paths with the gRPC services. Requests
+// are answered in their encoding, protobuf or JSON.
+func (r *Receiver) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/traces", func(w http.ResponseWriter, req *http.Request) {
+		serveHTTP(w, req, &collectortrace.ExportTraceServiceRequest{}, traceService{r: r}.Export)
+	})
+	mux.HandleFunc("POST /v1/metrics", func(w http.ResponseWriter, req *http.Request) {
+		serveHTTP(w, req, &collectormetrics.ExportMetricsServiceRequest{}, metricsService{r: r}.Export)
+	})
+	mux.HandleFunc("POST /v1/logs", func(w http.ResponseWriter, req *http.Request) {
+		serveHTTP(w, req, &collectorlogs.ExportLogsServiceRequest{}, logsService{r: r}.Export)
+	})
+
+	return mux
+}
+
+func serveHTTP[Req, Resp proto.Message](w http.ResponseWriter, req *http.Request, msg Req, export func(context.Context, Req) (Resp, error)) {
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	contentType, marshal, unmarshal := "application/x-protobuf", proto.Marshal, proto.Unmarshal
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		contentType, marshal, unmarshal = "application/json", protojson.Marshal, unmarshalOTLPJSON
+	}
+
+	data, err := io.ReadAll(body)
+	if err == nil {
+		err = unmarshal(data, msg)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := export(req.Context(), msg)
+	if err != nil {
+		st, _ := status.FromError(err)
+		data, _ = marshal(st.Proto())
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(httpStatus(st.Code()))
+		_, _ = w.Write(data)
+		return
+	}
+
+	data, err = marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// otlpJSONIDs are the fields OTLP/JSON encodes as hex strings, where
+// protojson expects base64 for bytes.
+var otlpJSONIDs = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+// unmarshalOTLPJSON decodes an OTLP/JSON message, whose trace and span IDs
+// are hex strings, into msg.
+func unmarshalOTLPJSON(data []byte, msg proto.Message) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+	if err := base64IDs(doc); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, msg)
+}
+
+// base64IDs rewrites the hex trace and span IDs found in v as base64.
+func base64IDs(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if id, ok := value.(string); ok && otlpJSONIDs[key] {
+				raw, err := hex.DecodeString(id)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				v[key] = base64.StdEncoding.EncodeToString(raw)
+				continue
+			}
+			if err := base64IDs(value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if err := base64IDs(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// httpStatus maps a gRPC code to the HTTP status OTLP/HTTP exporters treat
+// the same way, retryable or not.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded, codes.Canceled:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
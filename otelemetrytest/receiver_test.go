@@ -0,0 +1,105 @@
+package otelemetrytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/rorua/otelemetry"
+)
+
+// newExporting returns a Telemetry exporting every signal to collector.
+func newExporting(t *testing.T, collector otelemetry.Collector) otelemetry.Telemetry {
+	tel, err := otelemetry.New(otelemetry.Config{
+		Service:     otelemetry.Service{Name: "test-service"},
+		Collector:   collector,
+		Isolated:    true,
+		WithTraces:  true,
+		WithMetrics: true,
+		WithLogs:    true,
+	})
+	require.NoError(t, err)
+	return tel
+}
+
+func TestReceiver(t *testing.T) {
+	for _, protocol := range []otelemetry.Protocol{otelemetry.ProtocolGRPC, otelemetry.ProtocolHTTPProtobuf, otelemetry.ProtocolHTTPJSON} {
+		t.Run(string(protocol), func(t *testing.T) {
+			receiver := NewReceiver(t, protocol)
+			collector := receiver.Collector()
+			collector.Compression = otelemetry.CompressionGzip
+			tel := newExporting(t, collector)
+
+			ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+			counter, err := tel.Metric().Int64Counter("requests")
+			require.NoError(t, err)
+			counter.Add(ctx, 3)
+			tel.Log().Info(ctx, "message")
+			span.End()
+
+			require.NoError(t, tel.Shutdown(context.Background()))
+
+			spans := receiver.Spans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "span", spans[0].Name)
+
+			metrics := receiver.Metrics()
+			require.Len(t, metrics, 1)
+			assert.Equal(t, "requests", metrics[0].Name)
+			assert.Equal(t, int64(3), metrics[0].GetSum().DataPoints[0].GetAsInt())
+
+			records := receiver.LogRecords()
+			require.Len(t, records, 1)
+			assert.Equal(t, "message", records[0].Body.GetStringValue())
+			assert.Equal(t, spans[0].TraceId, records[0].TraceId)
+
+			assert.Equal(t, "test-service", receiver.TraceRequests()[0].ResourceSpans[0].Resource.Attributes[0].Value.GetStringValue())
+		})
+	}
+}
+
+func TestReceiverFail(t *testing.T) {
+	for _, protocol := range []otelemetry.Protocol{otelemetry.ProtocolGRPC, otelemetry.ProtocolHTTPProtobuf, otelemetry.ProtocolHTTPJSON} {
+		t.Run(string(protocol), func(t *testing.T) {
+			receiver := NewReceiver(t, protocol)
+			receiver.Fail(1, codes.Unavailable)
+
+			collector := receiver.Collector()
+			collector.Retry = &otelemetry.RetryPolicy{InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+			tel := newExporting(t, collector)
+
+			_, span := tel.Trace().StartSpan(context.Background(), "retried")
+			span.End()
+			require.NoError(t, tel.ForceFlush(context.Background()))
+			assert.Len(t, receiver.Spans(), 1, "the export is retried once the receiver recovers")
+
+			receiver.Fail(-1, codes.InvalidArgument)
+			_, span = tel.Trace().StartSpan(context.Background(), "rejected")
+			span.End()
+			assert.Error(t, tel.ForceFlush(context.Background()))
+			assert.Len(t, receiver.Spans(), 1)
+			assert.GreaterOrEqual(t, receiver.Requests(), 3)
+
+			receiver.Fail(0, codes.OK)
+			assert.NoError(t, tel.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestReceiverDelay(t *testing.T) {
+	receiver := NewReceiver(t, otelemetry.ProtocolGRPC)
+	receiver.SetDelay(time.Second)
+	tel := newExporting(t, receiver.Collector())
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, tel.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Empty(t, receiver.Spans())
+}
@@ -0,0 +1,243 @@
+package otelemetrytest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnvUpdateGolden rewrites the golden files compared by AssertGolden when set
+// to a true value, e.g. OTELEMETRYTEST_UPDATE=1 go test ./...
+const EnvUpdateGolden = "OTELEMETRYTEST_UPDATE"
+
+// SpanNode is a recorded span with the spans started under it.
+type SpanNode struct {
+	Span     tracetest.SpanStub
+	Children []*SpanNode
+}
+
+// ExpectedSpan describes a span of an expected tree. Zero fields are not
+// checked, except Children: the children must match in number and in start
+// order.
+type ExpectedSpan struct {
+	Name string
+	// Kind is checked unless SpanKindUnspecified.
+	Kind trace.SpanKind
+	// Status is checked unless codes.Unset.
+	Status codes.Code
+	// Attributes the span must have, among others.
+	Attributes []attribute.KeyValue
+	// Events are the names of every event of the span, in order, when non-nil.
+	Events   []string
+	Children []ExpectedSpan
+}
+
+// Trees returns the recorded spans as trees, children in start order. Spans
+// whose parent was not recorded are roots.
+func (t *Telemetry) Trees() []*SpanNode {
+	spans := t.Spans()
+	slices.SortStableFunc(spans, func(a, b tracetest.SpanStub) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+
+	nodes := make(map[trace.SpanID]*SpanNode, len(spans))
+	for _, span := range spans {
+		nodes[span.SpanContext.SpanID()] = &SpanNode{Span: span}
+	}
+
+	var roots []*SpanNode
+	for _, span := range spans {
+		node := nodes[span.SpanContext.SpanID()]
+		if parent, ok := nodes[span.Parent.SpanID()]; ok && span.Parent.IsValid() {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	return roots
+}
+
+// AssertTree asserts that a recorded root span matches want. On mismatch it
+// reports a diff between want and the recorded trees, reduced to the fields
+// want checks.
+func (t *Telemetry) AssertTree(want ExpectedSpan) bool {
+	t.tb.Helper()
+
+	expected := want.render()
+	roots := t.Trees()
+
+	var actual []string
+	for _, root := range roots {
+		if root.Span.Name != want.Name {
+			continue
+		}
+		got := root.render(&want)
+		if got == expected {
+			return true
+		}
+		actual = append(actual, got)
+	}
+	if len(actual) == 0 {
+		for _, root := range roots {
+			actual = append(actual, root.render(&ExpectedSpan{}))
+		}
+	}
+
+	return assert.Equal(t.tb, expected, strings.Join(actual, ""), "span tree %q", want.Name)
+}
+
+// AssertGolden compares every recorded tree with the golden file at path,
+// ignoring IDs and timestamps. With EnvUpdateGolden set, it writes the file
+// instead.
+func (t *Telemetry) AssertGolden(path string) bool {
+	t.tb.Helper()
+
+	var b strings.Builder
+	for _, root := range t.Trees() {
+		b.WriteString(root.render(nil))
+	}
+	actual := b.String()
+
+	if update, _ := strconv.ParseBool(os.Getenv(EnvUpdateGolden)); update {
+		require.NoError(t.tb, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t.tb, os.WriteFile(path, []byte(actual), 0o644))
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return assert.Fail(t.tb, fmt.Sprintf("golden file %s does not exist, run the test with %s=1 to create it", path, EnvUpdateGolden))
+	}
+	require.NoError(t.tb, err)
+
+	return assert.Equal(t.tb, string(expected), actual, "golden file %s", path)
+}
+
+// render writes the expected tree in the format of SpanNode.render.
+func (e ExpectedSpan) render() string {
+	var b strings.Builder
+	e.write(&b, 0)
+	return b.String()
+}
+
+func (e ExpectedSpan) write(b *strings.Builder, depth int) {
+	var attrs []string
+	for _, kv := range sortedAttributes(e.Attributes) {
+		attrs = append(attrs, renderAttribute(kv.Key, kv.Value))
+	}
+	writeLine(b, depth, e.Name, e.Kind, e.Status, "", attrs, e.Events)
+
+	for _, child := range e.Children {
+		child.write(b, depth+1)
+	}
+}
+
+// render writes the tree one span per line, children indented. With want,
+// only the fields want checks are written, so that the result equals
+// want.render() when the tree matches. Without, every field but the IDs and
+// timestamps is.
+func (n *SpanNode) render(want *ExpectedSpan) string {
+	var b strings.Builder
+	n.write(&b, 0, want)
+	return b.String()
+}
+
+func (n *SpanNode) write(b *strings.Builder, depth int, want *ExpectedSpan) {
+	var (
+		span        = n.Span
+		kind        = span.SpanKind
+		status      = span.Status.Code
+		description = span.Status.Description
+		attrs       []string
+		events      = make([]string, 0, len(span.Events))
+	)
+	for _, event := range span.Events {
+		events = append(events, event.Name)
+	}
+
+	set := attribute.NewSet(span.Attributes...)
+	if want == nil {
+		for _, kv := range set.ToSlice() {
+			attrs = append(attrs, renderAttribute(kv.Key, kv.Value))
+		}
+		if len(events) == 0 {
+			events = nil
+		}
+	} else {
+		if want.Kind == trace.SpanKindUnspecified {
+			kind = trace.SpanKindUnspecified
+		}
+		if want.Status == codes.Unset {
+			status = codes.Unset
+		}
+		description = ""
+		for _, kv := range sortedAttributes(want.Attributes) {
+			v, ok := set.Value(kv.Key)
+			if !ok {
+				attrs = append(attrs, string(kv.Key)+" missing")
+				continue
+			}
+			attrs = append(attrs, renderAttribute(kv.Key, v))
+		}
+		if want.Events == nil {
+			events = nil
+		}
+	}
+	writeLine(b, depth, span.Name, kind, status, description, attrs, events)
+
+	for i, child := range n.Children {
+		childWant := want
+		if want != nil {
+			childWant = &ExpectedSpan{}
+			if i < len(want.Children) {
+				childWant = &want.Children[i]
+			}
+		}
+		child.write(b, depth+1, childWant)
+	}
+}
+
+// renderAttribute writes an attribute with the type of its value, so that
+// 200 and "200" do not render the same.
+func renderAttribute(key attribute.Key, v attribute.Value) string {
+	return fmt.Sprintf("%s=%s (%s)", key, v.Emit(), v.Type())
+}
+
+func writeLine(b *strings.Builder, depth int, name string, kind trace.SpanKind, status codes.Code, description string, attrs, events []string) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(name)
+	if kind != trace.SpanKindUnspecified {
+		fmt.Fprintf(b, " [%s]", kind)
+	}
+	if status != codes.Unset {
+		fmt.Fprintf(b, " status=%s", status)
+		if description != "" {
+			fmt.Fprintf(b, " %q", description)
+		}
+	}
+	if len(attrs) > 0 {
+		fmt.Fprintf(b, " {%s}", strings.Join(attrs, ", "))
+	}
+	if events != nil {
+		fmt.Fprintf(b, " events=[%s]", strings.Join(events, ", "))
+	}
+	b.WriteString("\n")
+}
+
+func sortedAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	set := attribute.NewSet(attrs...)
+	return set.ToSlice()
+}
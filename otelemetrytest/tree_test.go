@@ -0,0 +1,126 @@
+package otelemetrytest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rorua/otelemetry"
+)
+
+// handle records the trace of a request loading a user from the cache, then
+// from the database after a cache miss.
+func handle(tel otelemetry.Telemetry) {
+	ctx, request := tel.Trace().StartSpan(context.Background(), "GET /users/{id}", trace.WithSpanKind(trace.SpanKindServer))
+	request.SetAttribute(attribute.String("http.route", "/users/{id}"), attribute.Int("http.status_code", 200))
+
+	_, cache := tel.Trace().StartSpan(ctx, "cache.get", trace.WithSpanKind(trace.SpanKindClient))
+	cache.AddErrorEvent("miss", errors.New("not found"))
+	cache.End()
+
+	_, query := tel.Trace().StartSpan(ctx, "db.query", trace.WithSpanKind(trace.SpanKindClient))
+	query.SetAttribute(attribute.String("db.system", "postgresql"))
+	query.End()
+
+	request.End()
+}
+
+func TestTrees(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+	_, other := tel.Trace().StartSpan(context.Background(), "cleanup")
+	other.End()
+
+	roots := tel.Trees()
+	require.Len(t, roots, 2)
+	assert.Equal(t, "GET /users/{id}", roots[0].Span.Name)
+	require.Len(t, roots[0].Children, 2)
+	assert.Equal(t, "cache.get", roots[0].Children[0].Span.Name)
+	assert.Equal(t, "db.query", roots[0].Children[1].Span.Name)
+	assert.Equal(t, "cleanup", roots[1].Span.Name)
+}
+
+func TestAssertTree(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+
+	tel.AssertTree(ExpectedSpan{
+		Name:       "GET /users/{id}",
+		Kind:       trace.SpanKindServer,
+		Attributes: []attribute.KeyValue{attribute.Int("http.status_code", 200)},
+		Children: []ExpectedSpan{
+			{Name: "cache.get", Status: codes.Error, Events: []string{"miss"}},
+			{Name: "db.query", Kind: trace.SpanKindClient, Events: []string{}},
+		},
+	})
+}
+
+func TestAssertTreeReportsDiff(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+
+	f := &failures{TB: t}
+	tel.tb = f
+
+	ok := tel.AssertTree(ExpectedSpan{
+		Name:       "GET /users/{id}",
+		Attributes: []attribute.KeyValue{attribute.Int("http.status_code", 404), attribute.String("user.id", "1")},
+		Children: []ExpectedSpan{
+			{Name: "cache.get", Status: codes.Ok},
+		},
+	})
+	assert.False(t, ok)
+	require.Len(t, f.messages, 1)
+	assert.Contains(t, f.messages[0], `-GET /users/{id} {http.status_code=404 (INT64), user.id=1 (STRING)}`)
+	assert.Contains(t, f.messages[0], `+GET /users/{id} {http.status_code=200 (INT64), user.id missing}`)
+	assert.Contains(t, f.messages[0], `-  cache.get status=Ok`)
+	assert.Contains(t, f.messages[0], `+  cache.get status=Error`)
+	assert.Contains(t, f.messages[0], `+  db.query`)
+
+	f.messages = nil
+	assert.False(t, tel.AssertTree(ExpectedSpan{Name: "POST /users"}))
+	require.Len(t, f.messages, 1)
+	assert.Contains(t, f.messages[0], `+GET /users/{id}`, "the recorded roots are listed")
+}
+
+func TestAssertTreeComparesAttributeTypes(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+
+	f := &failures{TB: t}
+	tel.tb = f
+
+	assert.False(t, tel.AssertTree(ExpectedSpan{
+		Name:       "GET /users/{id}",
+		Attributes: []attribute.KeyValue{attribute.String("http.status_code", "200")},
+	}))
+	require.Len(t, f.messages, 1)
+	assert.Contains(t, f.messages[0], `-GET /users/{id} {http.status_code=200 (STRING)}`)
+	assert.Contains(t, f.messages[0], `+GET /users/{id} {http.status_code=200 (INT64)}`)
+}
+
+func TestAssertGolden(t *testing.T) {
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+
+	tel.AssertGolden(filepath.Join("testdata", "handle.golden"))
+}
+
+func TestAssertGoldenMissingFile(t *testing.T) {
+	t.Setenv(EnvUpdateGolden, "")
+	tel := New(t, otelemetry.Config{})
+	handle(tel)
+
+	f := &failures{TB: t}
+	tel.tb = f
+	assert.False(t, tel.AssertGolden(filepath.Join(t.TempDir(), "missing.golden")))
+	require.Len(t, f.messages, 1)
+	assert.Contains(t, f.messages[0], EnvUpdateGolden+"=1")
+}
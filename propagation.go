@@ -2,28 +2,91 @@ package otelemetry
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/contrib/propagators/ot"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 )
 
+// Inject writes the span context of ctx, its tracestate included, and the
+// baggage into kv with the global propagator.
 func Inject(ctx context.Context, kv map[string]string) {
 	propagator := otel.GetTextMapPropagator()
 	propagator.Inject(ctx, propagation.MapCarrier(kv))
 }
 
+// Extract returns ctx with the remote span context and baggage read from kv
+// by the global propagator.
 func Extract(ctx context.Context, kv map[string]string) context.Context {
 	propagator := otel.GetTextMapPropagator()
 	return propagator.Extract(ctx, propagation.MapCarrier(kv))
 }
 
+// InjectHTTPHeaders is Inject for HTTP headers.
 func InjectHTTPHeaders(ctx context.Context, headers http.Header) {
 	propagator := otel.GetTextMapPropagator()
 	propagator.Inject(ctx, propagation.HeaderCarrier(headers))
 }
 
+// ExtractHTTPHeaders is Extract for HTTP headers.
 func ExtractHTTPHeaders(ctx context.Context, headers http.Header) context.Context {
 	propagator := otel.GetTextMapPropagator()
 	return propagator.Extract(ctx, propagation.HeaderCarrier(headers))
 }
+
+func (t *telemetry) Inject(ctx context.Context, kv map[string]string) {
+	t.propagator.Inject(ctx, propagation.MapCarrier(kv))
+}
+
+func (t *telemetry) Extract(ctx context.Context, kv map[string]string) context.Context {
+	return t.propagator.Extract(ctx, propagation.MapCarrier(kv))
+}
+
+func (t *telemetry) InjectHTTPHeaders(ctx context.Context, headers http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(headers))
+}
+
+func (t *telemetry) ExtractHTTPHeaders(ctx context.Context, headers http.Header) context.Context {
+	return t.propagator.Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// defaultPropagators is used when Config.Propagators is empty.
+var defaultPropagators = []string{"tracecontext", "baggage"}
+
+// propagators maps the names accepted in Config.Propagators
+// (as used by OTEL_PROPAGATORS) to their implementations.
+var propagators = map[string]propagation.TextMapPropagator{
+	"tracecontext": propagation.TraceContext{},
+	"baggage":      propagation.Baggage{},
+	"b3":           b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)),
+	"b3multi":      b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+	"jaeger":       jaeger.Jaeger{},
+	"xray":         xray.Propagator{},
+	"ottrace":      ot.OT{},
+	"none":         nil,
+}
+
+// newPropagator builds a composite propagator from the given format names.
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = defaultPropagators
+	}
+
+	var list []propagation.TextMapPropagator
+	for _, name := range names {
+		p, ok := propagators[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported propagator %q", name)
+		}
+		if p != nil {
+			list = append(list, p)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(list...), nil
+}
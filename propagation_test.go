@@ -0,0 +1,83 @@
+package otelemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newPropagatingTelemetry(t *testing.T, propagators ...string) Telemetry {
+	tel, err := New(Config{Service: Service{Name: "test-service"}, Propagators: propagators})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+	return tel
+}
+
+func TestPropagatorFormats(t *testing.T) {
+	tests := []struct {
+		propagator string
+		header     string
+		// the OT format carries the low 64 bits of the trace ID
+		traceIDBytes int
+	}{
+		{"tracecontext", "traceparent", 16},
+		{"b3", "b3", 16},
+		{"b3multi", "x-b3-traceid", 16},
+		{"jaeger", "uber-trace-id", 16},
+		{"xray", "X-Amzn-Trace-Id", 16},
+		{"ottrace", "ot-tracer-traceid", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.propagator, func(t *testing.T) {
+			tel := newPropagatingTelemetry(t, tt.propagator)
+
+			ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+			defer span.End()
+
+			carrier := make(map[string]string)
+			Inject(ctx, carrier)
+			assert.Contains(t, carrier, tt.header)
+
+			lowBytes := func(sc trace.SpanContext) []byte {
+				id := sc.TraceID()
+				return id[len(id)-tt.traceIDBytes:]
+			}
+
+			remote := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+			assert.True(t, remote.IsRemote())
+			assert.Equal(t, lowBytes(span.Span().SpanContext()), lowBytes(remote))
+			assert.Equal(t, span.Span().SpanContext().SpanID(), remote.SpanID())
+
+			headers := make(http.Header)
+			InjectHTTPHeaders(ctx, headers)
+			remote = trace.SpanContextFromContext(ExtractHTTPHeaders(context.Background(), headers))
+			assert.Equal(t, lowBytes(span.Span().SpanContext()), lowBytes(remote))
+		})
+	}
+}
+
+func TestCompositePropagator(t *testing.T) {
+	tel := newPropagatingTelemetry(t, "tracecontext", "b3")
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "span")
+	defer span.End()
+
+	carrier := make(map[string]string)
+	Inject(ctx, carrier)
+	assert.Contains(t, carrier, "traceparent")
+	assert.Contains(t, carrier, "b3")
+
+	// a legacy service sending B3 only is understood as well
+	remote := trace.SpanContextFromContext(Extract(context.Background(), map[string]string{"b3": carrier["b3"]}))
+	assert.Equal(t, span.Span().SpanContext().TraceID(), remote.TraceID())
+
+	tel = newPropagatingTelemetry(t, "none")
+	carrier = make(map[string]string)
+	Inject(ctx, carrier)
+	assert.Empty(t, carrier)
+}
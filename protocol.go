@@ -0,0 +1,225 @@
+package otelemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// isHTTP reports whether p exports over OTLP/HTTP.
+func (p Protocol) isHTTP() bool {
+	return p == ProtocolHTTPProtobuf || p == ProtocolHTTPJSON
+}
+
+// newHTTPClient returns the client of the OTLP/HTTP exporter of signal, or
+// nil when the exporter can build its own. A client is needed to add the
+// credentials headers and to encode http/json requests. The exporters ignore
+// their TLS and timeout options for a custom client, so tlsCfg and the
+// export timeout are set on the client here.
+func newHTTPClient(collector Collector, tlsCfg *tls.Config, signal string) *http.Client {
+	if collector.Credentials == nil && collector.Protocol != ProtocolHTTPJSON {
+		return nil
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tlsCfg
+
+	var transport http.RoundTripper = base
+	if collector.Protocol == ProtocolHTTPJSON {
+		transport = &jsonTransport{base: transport, signal: signal}
+	}
+	if collector.Credentials != nil {
+		transport = &credentialsTransport{base: transport, creds: cachedCredentials(collector.Credentials)}
+	}
+
+	return &http.Client{Transport: transport, Timeout: collector.timeout()}
+}
+
+// jsonTransport re-encodes the protobuf export requests of the OTLP/HTTP
+// exporters as OTLP/JSON, and the JSON responses of the collector back to
+// protobuf so that partial successes are still reported.
+type jsonTransport struct {
+	base   http.RoundTripper
+	signal string
+}
+
+func (t *jsonTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	gzipped := req.Header.Get("Content-Encoding") == "gzip"
+
+	body, err := readBody(req.Body, gzipped)
+	if err != nil {
+		return nil, fmt.Errorf("otelemetry: reading %s export request: %w", t.signal, err)
+	}
+
+	msg, _ := exportMessages(t.signal)
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("otelemetry: decoding %s export request: %w", t.signal, err)
+	}
+
+	body, err = marshalOTLPJSON(msg)
+	if err == nil && gzipped {
+		body, err = gzipBytes(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("otelemetry: encoding %s export request: %w", t.signal, err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := t.protobufResponse(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// protobufResponse replaces the JSON body of a successful export response
+// with its protobuf encoding. A body that cannot be decoded is left as is
+// and ignored by the exporter.
+func (t *jsonTransport) protobufResponse(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	_, msg := exportMessages(t.signal)
+	if len(body) == 0 || (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, msg) != nil {
+		return nil
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+
+	resp.Header.Set("Content-Type", "application/x-protobuf")
+	resp.ContentLength = int64(len(data))
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// exportMessages returns empty export request and response messages of signal.
+func exportMessages(signal string) (request, response proto.Message) {
+	switch signal {
+	case SignalMetrics:
+		return &collectormetrics.ExportMetricsServiceRequest{}, &collectormetrics.ExportMetricsServiceResponse{}
+	case SignalLogs:
+		return &collectorlogs.ExportLogsServiceRequest{}, &collectorlogs.ExportLogsServiceResponse{}
+	default:
+		return &collectortrace.ExportTraceServiceRequest{}, &collectortrace.ExportTraceServiceResponse{}
+	}
+}
+
+// otlpJSONIDs are the fields OTLP/JSON encodes as hex strings, where
+// protojson uses base64 for bytes.
+var otlpJSONIDs = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+// marshalOTLPJSON encodes msg as OTLP/JSON: the protobuf JSON mapping with
+// enums as integers and trace and span IDs as hex strings.
+func marshalOTLPJSON(msg proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := hexIDs(doc); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+// hexIDs rewrites the base64 trace and span IDs found in v as hex.
+func hexIDs(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if id, ok := value.(string); ok && otlpJSONIDs[key] {
+				raw, err := base64.StdEncoding.DecodeString(id)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				v[key] = hex.EncodeToString(raw)
+				continue
+			}
+			if err := hexIDs(value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if err := hexIDs(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readBody reads and closes an export request body, gunzipping it if needed.
+func readBody(body io.ReadCloser, gzipped bool) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+
+	r := io.Reader(body)
+	if gzipped {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return io.ReadAll(r)
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,178 @@
+package otelemetry
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpReceiver is a stand-in OTLP/HTTP receiver recording the request paths
+// with their headers, and the decoded trace export requests.
+type httpReceiver struct {
+	mu      sync.Mutex
+	paths   []string
+	headers map[string]http.Header
+	spans   []string
+	server  *httptest.Server
+}
+
+func newHTTPReceiver(t *testing.T) *httpReceiver {
+	r := &httpReceiver{headers: make(map[string]http.Header)}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.paths = append(r.paths, req.URL.Path)
+		r.headers[req.URL.Path] = req.Header.Clone()
+
+		var traces collectortrace.ExportTraceServiceRequest
+		if req.URL.Path == "/custom/traces" && proto.Unmarshal(body, &traces) == nil {
+			for _, rs := range traces.ResourceSpans {
+				for _, ss := range rs.ScopeSpans {
+					for _, span := range ss.Spans {
+						r.spans = append(r.spans, span.Name)
+					}
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+func (r *httpReceiver) collector(t *testing.T) Collector {
+	host, port, err := net.SplitHostPort(r.server.Listener.Addr().String())
+	require.NoError(t, err)
+	return Collector{Host: host, Port: port, Protocol: ProtocolHTTPProtobuf}
+}
+
+func TestNewWithHTTPProtobuf(t *testing.T) {
+	receiver := newHTTPReceiver(t)
+
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Collector:     receiver.collector(t),
+		WithTraces:    true,
+		WithMetrics:   true,
+		WithLogs:      true,
+		TracerOptions: TracerOptions{URLPath: "/custom/traces"},
+	})
+	require.NoError(t, err)
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "http-span")
+	span.End()
+
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	tel.Log().Info(ctx, "message")
+
+	require.NoError(t, tel.Shutdown(context.Background()))
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	assert.ElementsMatch(t, []string{"/custom/traces", "/v1/metrics", "/v1/logs"}, receiver.paths)
+	assert.Equal(t, []string{"http-span"}, receiver.spans)
+}
+
+func TestNewWithHTTPJSON(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionGzip} {
+		t.Run(string(compression), func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				types    = make(map[string]string)
+				requests = make(map[string]map[string]any)
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body := io.Reader(req.Body)
+				if req.Header.Get("Content-Encoding") == "gzip" {
+					gz, err := gzip.NewReader(req.Body)
+					if !assert.NoError(t, err) {
+						return
+					}
+					body = gz
+				}
+
+				var doc map[string]any
+				if !assert.NoError(t, json.NewDecoder(body).Decode(&doc)) {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				types[req.URL.Path] = req.Header.Get("Content-Type")
+				requests[req.URL.Path] = doc
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"partialSuccess":{}}`))
+			}))
+			t.Cleanup(server.Close)
+
+			host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+			require.NoError(t, err)
+
+			tel, err := New(Config{
+				Service: Service{Name: "test-service"},
+				Collector: Collector{
+					Host:         host,
+					Port:         port,
+					Protocol:     ProtocolHTTPJSON,
+					ExportPolicy: ExportPolicy{Compression: compression},
+				},
+				WithTraces:  true,
+				WithMetrics: true,
+				WithLogs:    true,
+			})
+			require.NoError(t, err)
+
+			ctx, span := tel.Trace().StartSpan(context.Background(), "json-span", trace.WithSpanKind(trace.SpanKindServer))
+			counter, err := tel.Metric().Int64Counter("json.counter")
+			require.NoError(t, err)
+			counter.Add(ctx, 1)
+			tel.Log().Info(ctx, "message")
+			span.End()
+
+			require.NoError(t, tel.Shutdown(context.Background()))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, path := range []string{"/v1/traces", "/v1/metrics", "/v1/logs"} {
+				assert.Equal(t, "application/json", types[path], path)
+			}
+
+			traces := requests["/v1/traces"]
+			require.NotNil(t, traces)
+			got := traces["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+			assert.Equal(t, "json-span", got["name"])
+			assert.Equal(t, span.Span().SpanContext().TraceID().String(), got["traceId"])
+			assert.Equal(t, span.Span().SpanContext().SpanID().String(), got["spanId"])
+			assert.Equal(t, float64(trace.SpanKindServer), got["kind"], "enums are encoded as integers")
+		})
+	}
+}
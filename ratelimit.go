@@ -0,0 +1,130 @@
+package otelemetry
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rateLimitedSampler samples at most perSecond root spans per second with a
+// token bucket. When adaptive, it also samples roots by trace ID at a ratio
+// adjusted every second to the observed root throughput, so the budget is
+// spread evenly instead of being spent at the start of each burst.
+//
+// Spans with a parent follow the parent's decision. The probability a root
+// was sampled with, estimated over the previous second, is recorded as the
+// th value of the ot tracestate entry.
+type rateLimitedSampler struct {
+	perSecond float64
+	adaptive  bool
+	now       func() time.Time
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	windowStart time.Time
+	seen        int
+	sampled     int
+	rate        float64 // smoothed roots per second
+	probability float64
+	ratio       sdktrace.Sampler // adaptive ratio, nil when sampling all roots
+}
+
+func newRateLimitedSampler(perSecond float64, adaptive bool, now func() time.Time) *rateLimitedSampler {
+	return &rateLimitedSampler{
+		perSecond:   perSecond,
+		adaptive:    adaptive,
+		now:         now,
+		tokens:      math.Max(perSecond, 1),
+		probability: 1,
+	}
+}
+
+func (s *rateLimitedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	if parent.IsValid() {
+		decision := sdktrace.Drop
+		if parent.IsSampled() {
+			decision = sdktrace.RecordAndSample
+		}
+		return sdktrace.SamplingResult{Decision: decision, Tracestate: parent.TraceState()}
+	}
+
+	sampled, probability := s.take(p.TraceID)
+	if !sampled {
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: parent.TraceState()}
+	}
+
+	return sdktrace.SamplingResult{
+		Decision:   sdktrace.RecordAndSample,
+		Tracestate: withOTValue(parent.TraceState(), "th", encodeThreshold(probability)),
+	}
+}
+
+// take decides on a root span and returns the current sampling probability.
+func (s *rateLimitedSampler) take(traceID trace.TraceID) (bool, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.roll(now)
+	s.seen++
+
+	// the token bucket holds up to one second of budget
+	if !s.last.IsZero() {
+		s.tokens = math.Min(math.Max(s.perSecond, 1), s.tokens+now.Sub(s.last).Seconds()*s.perSecond)
+	}
+	s.last = now
+
+	if s.ratio != nil && s.ratio.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID}).Decision != sdktrace.RecordAndSample {
+		return false, s.probability
+	}
+
+	if s.tokens < 1 {
+		return false, s.probability
+	}
+	s.tokens--
+	s.sampled++
+
+	return true, s.probability
+}
+
+// roll starts a new one second window, updating the probability from the
+// window that ended.
+func (s *rateLimitedSampler) roll(now time.Time) {
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+		return
+	}
+
+	elapsed := now.Sub(s.windowStart)
+	if elapsed < time.Second {
+		return
+	}
+
+	if s.adaptive {
+		rate := float64(s.seen) / elapsed.Seconds()
+		if s.rate == 0 {
+			s.rate = rate
+		} else {
+			s.rate = (s.rate + rate) / 2
+		}
+		s.probability, s.ratio = 1, nil
+		if s.rate > s.perSecond {
+			s.probability = s.perSecond / s.rate
+			s.ratio = sdktrace.TraceIDRatioBased(s.probability)
+		}
+	} else if s.seen > 0 {
+		s.probability = float64(s.sampled) / float64(s.seen)
+	}
+
+	s.windowStart, s.seen, s.sampled = now, 0, 0
+}
+
+func (s *rateLimitedSampler) Description() string {
+	return fmt.Sprintf("RateLimited{perSecond:%g,adaptive:%t}", s.perSecond, s.adaptive)
+}
@@ -0,0 +1,147 @@
+package otelemetry
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func randomTraceID(rnd *rand.Rand) trace.TraceID {
+	var id trace.TraceID
+	rnd.Read(id[:])
+	return id
+}
+
+func TestEncodeThreshold(t *testing.T) {
+	tests := map[float64]string{
+		1:    "0",
+		0.5:  "8",
+		0.25: "c",
+		0.1:  "e6666666666666",
+	}
+	for p, th := range tests {
+		assert.Equal(t, th, encodeThreshold(p), p)
+	}
+}
+
+func TestRateLimitedSamplerBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	sampler := newRateLimitedSampler(10, false, clock.Now)
+	rnd := rand.New(rand.NewSource(1))
+
+	burst := func() (sampled []sdktrace.SamplingResult) {
+		for i := 0; i < 100; i++ {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       randomTraceID(rnd),
+			})
+			if result.Decision == sdktrace.RecordAndSample {
+				sampled = append(sampled, result)
+			}
+		}
+		return sampled
+	}
+
+	first := burst()
+	require.Len(t, first, 10)
+	th, _ := otValue(first[0].Tracestate, "th")
+	assert.Equal(t, "0", th, "nothing was dropped yet")
+
+	clock.Advance(time.Second)
+	second := burst()
+	require.Len(t, second, 10)
+	th, _ = otValue(second[0].Tracestate, "th")
+	assert.Equal(t, encodeThreshold(0.1), th, "10 of 100 roots were sampled in the previous second")
+}
+
+func TestRateLimitedSamplerFollowsParent(t *testing.T) {
+	sampler := newRateLimitedSampler(1, false, time.Now)
+	ts, err := trace.ParseTraceState("ot=th:8,vendor=value")
+	require.NoError(t, err)
+
+	for _, flags := range []trace.TraceFlags{trace.FlagsSampled, 0} {
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			TraceState: ts,
+		}))
+
+		// more children than the budget
+		for i := 0; i < 5; i++ {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, TraceID: trace.TraceID{1}})
+			assert.Equal(t, flags.IsSampled(), result.Decision == sdktrace.RecordAndSample)
+			assert.Equal(t, ts, result.Tracestate)
+		}
+	}
+}
+
+func TestRateLimitedSamplerAdaptive(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	sampler := newRateLimitedSampler(10, true, clock.Now)
+	rnd := rand.New(rand.NewSource(1))
+
+	// one second at 1000 roots per second, sampled one millisecond apart
+	second := func() (sampled []int) {
+		for i := 0; i < 1000; i++ {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       randomTraceID(rnd),
+			})
+			if result.Decision == sdktrace.RecordAndSample {
+				sampled = append(sampled, i)
+			}
+			clock.Advance(time.Millisecond)
+		}
+		return sampled
+	}
+
+	// the first second spends the initial budget on the first roots
+	first := second()
+	require.Greater(t, len(first), 10)
+	assert.Equal(t, 9, first[9])
+
+	// then the ratio follows the throughput and spreads the samples
+	adapted := second()
+	assert.InDelta(t, 0.01, sampler.probability, 0.001)
+	require.NotEmpty(t, adapted)
+	assert.LessOrEqual(t, len(adapted), 20)
+	assert.Greater(t, adapted[len(adapted)-1], 500)
+}
+
+func TestRateLimitedSamplerWithStartSpan(t *testing.T) {
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{Type: SamplerRateLimited, PerSecond: 1}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	ctx, root := tel.Trace().StartSpan(context.Background(), "root")
+	_, child := tel.Trace().StartSpan(ctx, "child")
+	_, dropped := tel.Trace().StartSpan(context.Background(), "another root")
+
+	assert.True(t, root.Span().IsRecording())
+	assert.True(t, child.Span().IsRecording())
+	assert.False(t, dropped.Span().IsRecording())
+	assert.Equal(t, "th:0", root.Span().SpanContext().TraceState().Get("ot"))
+
+	child.End()
+	root.End()
+	dropped.End()
+}
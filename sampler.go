@@ -0,0 +1,261 @@
+package otelemetry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplerType selects the sampling strategy of a Sampler.
+type SamplerType string
+
+const (
+	// SamplerAlwaysOn records and exports every span.
+	SamplerAlwaysOn SamplerType = "always_on"
+	// SamplerAlwaysOff drops every span.
+	SamplerAlwaysOff SamplerType = "always_off"
+	// SamplerTraceIDRatio samples Sampler.Ratio of the traces, by trace ID.
+	SamplerTraceIDRatio SamplerType = "traceidratio"
+	// SamplerParentBased follows the decision of the parent span and uses
+	// Sampler.Root for root spans.
+	SamplerParentBased SamplerType = "parentbased"
+	// SamplerRuleBased applies the first of Sampler.Rules matching a root
+	// span, or Sampler.Fallback when none does. Spans with a parent follow
+	// the parent's decision.
+	SamplerRuleBased SamplerType = "rulebased"
+	// SamplerRateLimited samples at most Sampler.PerSecond root traces per
+	// second, optionally adapting its ratio to the throughput.
+	SamplerRateLimited SamplerType = "ratelimited"
+	// SamplerConsistentProbability samples Sampler.Ratio of the traces with
+	// OpenTelemetry consistent probability sampling, so that every service
+	// of a trace takes the same decision at equal ratios.
+	SamplerConsistentProbability SamplerType = "consistentprobability"
+)
+
+// Sampler configures which spans are recorded and exported.
+//
+// For example, a parent-based sampler keeping 10% of the new traces:
+//
+//	&Sampler{
+//		Type: SamplerParentBased,
+//		Root: &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.1},
+//	}
+type Sampler struct {
+	Type SamplerType
+	// Ratio of the traces sampled by SamplerTraceIDRatio and
+	// SamplerConsistentProbability, in [0, 1].
+	Ratio float64
+
+	// Root samples the spans without a parent. Defaults to SamplerAlwaysOn.
+	Root *Sampler
+	// RemoteParentSampled samples the spans whose parent, propagated from
+	// another process, is sampled. Defaults to SamplerAlwaysOn.
+	RemoteParentSampled *Sampler
+	// RemoteParentNotSampled samples the spans whose remote parent is not
+	// sampled. Defaults to SamplerAlwaysOff.
+	RemoteParentNotSampled *Sampler
+	// LocalParentSampled samples the spans whose parent, started in this
+	// process, is sampled. Defaults to SamplerAlwaysOn.
+	LocalParentSampled *Sampler
+	// LocalParentNotSampled samples the spans whose local parent is not
+	// sampled. Defaults to SamplerAlwaysOff.
+	LocalParentNotSampled *Sampler
+
+	// Rules of SamplerRuleBased, evaluated in order.
+	Rules []SamplingRule
+	// Fallback samples the spans matching no rule. Defaults to SamplerAlwaysOn.
+	Fallback *Sampler
+
+	// PerSecond is the budget of SamplerRateLimited, in sampled root traces
+	// per second. Spans with a parent follow the parent's decision.
+	PerSecond float64
+	// Adaptive makes SamplerRateLimited sample roots by trace ID at a ratio
+	// following the observed throughput, spreading the budget over each
+	// second instead of spending it on the start of a burst.
+	Adaptive bool
+}
+
+// SamplingRule samples the spans matching all of its criteria at Ratio.
+// Criteria left empty match any span.
+//
+// Rules see the name, kind and attributes given when the span starts, e.g.
+// with trace.WithSpanKind and trace.WithAttributes passed to Trace.StartSpan.
+type SamplingRule struct {
+	// Name is a pattern of span names where * matches any characters,
+	// e.g. "GET /health*".
+	Name string
+	// Kind of the span. trace.SpanKindUnspecified matches any kind.
+	Kind trace.SpanKind
+	// Attributes the span must start with, compared as strings,
+	// e.g. {"http.route": "/healthz"}.
+	Attributes map[string]string
+	// Ratio of the matching traces sampled, in [0, 1]. Zero drops them.
+	Ratio float64
+}
+
+// sampler builds the SDK sampler. The default, when s is nil, is always on.
+func (s *Sampler) sampler() sdktrace.Sampler {
+	if s == nil {
+		return sdktrace.AlwaysSample()
+	}
+
+	switch s.Type {
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample()
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(s.Ratio)
+	case SamplerParentBased:
+		var opts []sdktrace.ParentBasedSamplerOption
+		if s.RemoteParentSampled != nil {
+			opts = append(opts, sdktrace.WithRemoteParentSampled(s.RemoteParentSampled.sampler()))
+		}
+		if s.RemoteParentNotSampled != nil {
+			opts = append(opts, sdktrace.WithRemoteParentNotSampled(s.RemoteParentNotSampled.sampler()))
+		}
+		if s.LocalParentSampled != nil {
+			opts = append(opts, sdktrace.WithLocalParentSampled(s.LocalParentSampled.sampler()))
+		}
+		if s.LocalParentNotSampled != nil {
+			opts = append(opts, sdktrace.WithLocalParentNotSampled(s.LocalParentNotSampled.sampler()))
+		}
+		return sdktrace.ParentBased(s.Root.sampler(), opts...)
+	case SamplerRuleBased:
+		return sdktrace.ParentBased(newRuleBasedSampler(s.Rules, s.Fallback.sampler()))
+	case SamplerRateLimited:
+		return newRateLimitedSampler(s.PerSecond, s.Adaptive, time.Now)
+	case SamplerConsistentProbability:
+		return newConsistentSampler(s.Ratio)
+	default:
+		return sdktrace.AlwaysSample()
+	}
+}
+
+// validate checks s and its nested samplers, naming them after path.
+func (s *Sampler) validate(path string) []error {
+	if s == nil {
+		return nil
+	}
+
+	var errs []error
+
+	switch s.Type {
+	case SamplerAlwaysOn, SamplerAlwaysOff:
+	case SamplerTraceIDRatio, SamplerConsistentProbability:
+		if s.Ratio < 0 || s.Ratio > 1 {
+			errs = append(errs, fmt.Errorf("%s: ratio %v out of range [0, 1]", path, s.Ratio))
+		}
+	case SamplerParentBased:
+		for _, nested := range []struct {
+			name    string
+			sampler *Sampler
+		}{
+			{"root", s.Root},
+			{"remote parent sampled", s.RemoteParentSampled},
+			{"remote parent not sampled", s.RemoteParentNotSampled},
+			{"local parent sampled", s.LocalParentSampled},
+			{"local parent not sampled", s.LocalParentNotSampled},
+		} {
+			errs = append(errs, nested.sampler.validate(path+" "+nested.name)...)
+		}
+	case SamplerRuleBased:
+		for i, rule := range s.Rules {
+			if rule.Ratio < 0 || rule.Ratio > 1 {
+				errs = append(errs, fmt.Errorf("%s rule %d: ratio %v out of range [0, 1]", path, i, rule.Ratio))
+			}
+		}
+		errs = append(errs, s.Fallback.validate(path+" fallback")...)
+	case SamplerRateLimited:
+		if s.PerSecond <= 0 {
+			errs = append(errs, fmt.Errorf("%s: per second budget %v must be positive", path, s.PerSecond))
+		}
+	case "":
+		errs = append(errs, fmt.Errorf("%s: type is required", path))
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported type %q", path, s.Type))
+	}
+
+	if s.Type != SamplerParentBased && (s.Root != nil || s.RemoteParentSampled != nil || s.RemoteParentNotSampled != nil ||
+		s.LocalParentSampled != nil || s.LocalParentNotSampled != nil) {
+		errs = append(errs, fmt.Errorf("%s: parent samplers are only used by the parent-based sampler", path))
+	}
+
+	if s.Type != SamplerRuleBased && (len(s.Rules) > 0 || s.Fallback != nil) {
+		errs = append(errs, fmt.Errorf("%s: rules are only used by the rule-based sampler", path))
+	}
+
+	return errs
+}
+
+// uses reports whether s or one of its nested samplers is of type t.
+func (s *Sampler) uses(t SamplerType) bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Type == t {
+		return true
+	}
+
+	for _, nested := range []*Sampler{s.Root, s.RemoteParentSampled, s.RemoteParentNotSampled, s.LocalParentSampled, s.LocalParentNotSampled, s.Fallback} {
+		if nested.uses(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ruleBasedSampler samples a root span with the first matching rule.
+type ruleBasedSampler struct {
+	rules    []samplingRule
+	fallback sdktrace.Sampler
+}
+
+type samplingRule struct {
+	SamplingRule
+	name    *regexp.Regexp
+	sampler sdktrace.Sampler
+}
+
+func newRuleBasedSampler(rules []SamplingRule, fallback sdktrace.Sampler) *ruleBasedSampler {
+	s := &ruleBasedSampler{fallback: fallback}
+	for _, rule := range rules {
+		compiled := samplingRule{SamplingRule: rule, sampler: sdktrace.TraceIDRatioBased(rule.Ratio)}
+		if rule.Name != "" {
+			compiled.name = regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(rule.Name), `\*`, ".*") + "$")
+		}
+		s.rules = append(s.rules, compiled)
+	}
+
+	return s
+}
+
+func (s *ruleBasedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, rule := range s.rules {
+		if rule.matches(p) {
+			return rule.sampler.ShouldSample(p)
+		}
+	}
+
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleBasedSampler) Description() string {
+	return fmt.Sprintf("RuleBased{rules:%d,fallback:%s}", len(s.rules), s.fallback.Description())
+}
+
+func (r samplingRule) matches(p sdktrace.SamplingParameters) bool {
+	if r.name != nil && !r.name.MatchString(p.Name) {
+		return false
+	}
+
+	if r.Kind != trace.SpanKindUnspecified && r.Kind != p.Kind {
+		return false
+	}
+
+	return hasAttributes(p.Attributes, r.Attributes)
+}
@@ -0,0 +1,195 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSamplerDecisions(t *testing.T) {
+	var (
+		low  = trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+		high = trace.TraceID{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	)
+
+	parent := func(sampled, remote bool) context.Context {
+		var flags trace.TraceFlags
+		if sampled {
+			flags = trace.FlagsSampled
+		}
+		return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    low,
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     remote,
+		}))
+	}
+
+	parentBased := &Sampler{
+		Type:                   SamplerParentBased,
+		Root:                   &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.5},
+		RemoteParentNotSampled: &Sampler{Type: SamplerAlwaysOn},
+		LocalParentSampled:     &Sampler{Type: SamplerAlwaysOff},
+	}
+
+	tests := []struct {
+		name    string
+		sampler *Sampler
+		ctx     context.Context
+		traceID trace.TraceID
+		sampled bool
+	}{
+		{"default", nil, context.Background(), high, true},
+		{"always on", &Sampler{Type: SamplerAlwaysOn}, context.Background(), high, true},
+		{"always off", &Sampler{Type: SamplerAlwaysOff}, context.Background(), low, false},
+		{"ratio below", &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.5}, context.Background(), low, true},
+		{"ratio above", &Sampler{Type: SamplerTraceIDRatio, Ratio: 0.5}, context.Background(), high, false},
+		{"parent based root", parentBased, context.Background(), high, false},
+		{"parent based remote sampled", parentBased, parent(true, true), low, true},
+		{"parent based remote not sampled", parentBased, parent(false, true), low, true},
+		{"parent based local sampled", parentBased, parent(true, false), low, false},
+		{"parent based local not sampled", parentBased, parent(false, false), low, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.sampler.sampler().ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: tt.ctx,
+				TraceID:       tt.traceID,
+				Name:          "span",
+			})
+			assert.Equal(t, tt.sampled, result.Decision == sdktrace.RecordAndSample)
+		})
+	}
+}
+
+func TestSamplerAppliedToProviders(t *testing.T) {
+	for _, withTraces := range []bool{true, false} {
+		cfg := Config{
+			Service:       Service{Name: "test-service"},
+			Collector:     Collector{Host: "localhost", Port: "4317"},
+			WithTraces:    withTraces,
+			TracerOptions: TracerOptions{Sampler: &Sampler{Type: SamplerAlwaysOff}},
+		}
+
+		tel, err := New(cfg)
+		require.NoError(t, err)
+
+		_, span := tel.Trace().StartSpan(context.Background(), "span")
+		assert.False(t, span.Span().IsRecording(), "otlp: %v", withTraces)
+		span.End()
+
+		require.NoError(t, tel.Shutdown(context.Background()))
+	}
+}
+
+func TestSamplerValidation(t *testing.T) {
+	err := Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{
+			Type:               SamplerParentBased,
+			Root:               &Sampler{Type: SamplerTraceIDRatio, Ratio: 1.5},
+			LocalParentSampled: &Sampler{Type: "sometimes"},
+		}},
+	}.Validate()
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "sampler root: ratio 1.5 out of range [0, 1]")
+	assert.ErrorContains(t, err, `sampler local parent sampled: unsupported type "sometimes"`)
+
+	err = Config{
+		Service:       Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{Type: SamplerAlwaysOn, Root: &Sampler{Type: SamplerAlwaysOff}}},
+	}.Validate()
+	assert.ErrorContains(t, err, "sampler: parent samplers are only used by the parent-based sampler")
+}
+
+func TestRuleBasedSampler(t *testing.T) {
+	tel, err := New(Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{
+			Type: SamplerRuleBased,
+			Rules: []SamplingRule{
+				{Attributes: map[string]string{"http.route": "/healthz"}},
+				{Name: "GET /metrics*", Kind: trace.SpanKindServer},
+				{Name: "db.*", Ratio: 1},
+			},
+			Fallback: &Sampler{Type: SamplerAlwaysOff},
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	tests := []struct {
+		name    string
+		span    string
+		opts    []trace.SpanStartOption
+		sampled bool
+	}{
+		{"attribute drop", "GET", []trace.SpanStartOption{trace.WithAttributes(Attribute("http.route", "/healthz"))}, false},
+		{"name and kind drop", "GET /metrics/prometheus", []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindServer)}, false},
+		{"name pattern keep", "db.query", nil, true},
+		{"kind mismatch falls back", "GET /metrics", []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindClient)}, false},
+		{"attribute mismatch falls through", "db.exec", []trace.SpanStartOption{trace.WithAttributes(Attribute("http.route", "/users"))}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, span := tel.Trace().StartSpan(context.Background(), tt.span, tt.opts...)
+			defer span.End()
+			assert.Equal(t, tt.sampled, span.Span().IsRecording())
+		})
+	}
+}
+
+func TestRuleBasedSamplerChildrenFollowParent(t *testing.T) {
+	tel, err := New(Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{
+			Exporter: ExporterMemory,
+			Sampler: &Sampler{
+				Type:  SamplerRuleBased,
+				Rules: []SamplingRule{{Name: "GET /healthz"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	// the child matches no rule, but its root was dropped
+	ctx, root := tel.Trace().StartSpan(context.Background(), "GET /healthz")
+	_, child := tel.Trace().StartSpan(ctx, "db.query")
+	assert.False(t, child.Span().IsRecording())
+	child.End()
+	root.End()
+
+	ctx, root = tel.Trace().StartSpan(context.Background(), "GET /users")
+	_, child = tel.Trace().StartSpan(ctx, "GET /healthz")
+	assert.True(t, child.Span().IsRecording())
+	child.End()
+	root.End()
+
+	spans := tel.Memory().Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GET /healthz", spans[0].Name)
+	assert.Equal(t, "GET /users", spans[1].Name)
+}
+
+func TestRuleBasedSamplerValidation(t *testing.T) {
+	err := Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{Sampler: &Sampler{
+			Type:     SamplerRuleBased,
+			Rules:    []SamplingRule{{Ratio: 0.5}, {Ratio: -1}},
+			Fallback: &Sampler{Type: SamplerTraceIDRatio, Ratio: 2},
+		}},
+	}.Validate()
+
+	assert.ErrorContains(t, err, "sampler rule 1: ratio -1 out of range [0, 1]")
+	assert.ErrorContains(t, err, "sampler fallback: ratio 2 out of range [0, 1]")
+	assert.NotContains(t, err.Error(), "rule 0")
+}
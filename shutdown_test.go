@@ -0,0 +1,128 @@
+package otelemetry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// stubSpanProcessor runs shutdown when the tracer provider shuts down.
+type stubSpanProcessor struct {
+	sdktrace.SpanProcessor
+	shutdown func(ctx context.Context) error
+	calls    atomic.Int32
+}
+
+func (p *stubSpanProcessor) Shutdown(ctx context.Context) error {
+	p.calls.Add(1)
+	return p.shutdown(ctx)
+}
+
+// stubLogProcessor runs shutdown when the logger provider shuts down.
+type stubLogProcessor struct {
+	sdklog.Processor
+	shutdown func(ctx context.Context) error
+}
+
+func (p *stubLogProcessor) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+func newStubbedTelemetry(t *testing.T, spans *stubSpanProcessor, logs *stubLogProcessor) Telemetry {
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Isolated:      true,
+		TracerOptions: TracerOptions{ProviderOption: []sdktrace.TracerProviderOption{sdktrace.WithSpanProcessor(spans)}},
+		LoggerOptions: LoggerOptions{ProviderOption: []sdklog.LoggerProviderOption{sdklog.WithProcessor(logs)}},
+	})
+	require.NoError(t, err)
+	return tel
+}
+
+func TestShutdownJoinsSignalErrors(t *testing.T) {
+	spans := &stubSpanProcessor{shutdown: func(context.Context) error { return errors.New("span exporter down") }}
+	logs := &stubLogProcessor{shutdown: func(context.Context) error { return errors.New("log exporter down") }}
+	tel := newStubbedTelemetry(t, spans, logs)
+
+	err := tel.Shutdown(context.Background())
+	assert.ErrorContains(t, err, "otelemetry: shutting down traces: span exporter down")
+	assert.ErrorContains(t, err, "otelemetry: shutting down logs: log exporter down")
+	assert.NotContains(t, err.Error(), "metrics")
+
+	// idempotent
+	assert.Equal(t, err, tel.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), spans.calls.Load())
+}
+
+func TestShutdownRunsInParallel(t *testing.T) {
+	logsStarted := make(chan struct{})
+
+	// the trace provider only completes once the logger provider shuts down too
+	spans := &stubSpanProcessor{shutdown: func(ctx context.Context) error {
+		select {
+		case <-logsStarted:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}}
+	logs := &stubLogProcessor{shutdown: func(context.Context) error {
+		close(logsStarted)
+		return nil
+	}}
+	tel := newStubbedTelemetry(t, spans, logs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, tel.Shutdown(ctx))
+}
+
+func TestForceFlush(t *testing.T) {
+	receiver := newHTTPReceiver(t)
+
+	tel, err := New(Config{
+		Service:     Service{Name: "test-service"},
+		Collector:   receiver.collector(t),
+		WithTraces:  true,
+		WithMetrics: true,
+		WithLogs:    true,
+		Isolated:    true,
+		TracerOptions: TracerOptions{
+			URLPath:                  "/custom/traces",
+			BatchSpanProcessorOption: []sdktrace.BatchSpanProcessorOption{sdktrace.WithBatchTimeout(time.Hour)},
+		},
+		LoggerOptions: LoggerOptions{
+			BatchProcessorOption: []sdklog.BatchProcessorOption{sdklog.WithExportInterval(time.Hour)},
+		},
+		MetricOptions: MetricOptions{PeriodicInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "flushed-span")
+	span.End()
+	counter, err := tel.Metric().Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+	tel.Log().Info(ctx, "message")
+
+	require.NoError(t, tel.ForceFlush(context.Background()))
+
+	receiver.mu.Lock()
+	assert.ElementsMatch(t, []string{"/custom/traces", "/v1/metrics", "/v1/logs"}, receiver.paths)
+	assert.Equal(t, []string{"flushed-span"}, receiver.spans)
+	receiver.mu.Unlock()
+
+	// still running after the flush
+	_, span = tel.Trace().StartSpan(context.Background(), "after-flush")
+	assert.True(t, span.Span().IsRecording())
+	span.End()
+
+	require.NoError(t, tel.Shutdown(context.Background()))
+}
@@ -0,0 +1,67 @@
+package otelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+)
+
+// DefaultGracePeriod bounds the graceful shutdown of ShutdownOnSignal when
+// no grace period is given.
+const DefaultGracePeriod = 30 * time.Second
+
+// ShutdownHook releases a resource of the service, such as an HTTP server,
+// before the telemetry shuts down. It must return once ctx is done.
+type ShutdownHook func(ctx context.Context) error
+
+func (t *telemetry) OnShutdown(hook ShutdownHook) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.hooks = append(t.hooks, hook)
+}
+
+func (t *telemetry) ShutdownOnSignal(ctx context.Context, grace time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+
+	var attrs []log.KeyValue
+	select {
+	case sig := <-received:
+		attrs = append(attrs, LogAttribute("signal", sig.String()))
+	case <-ctx.Done():
+	}
+
+	// the grace period is not cut short by ctx, which may be the one done
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), grace)
+	defer cancel()
+
+	t.mu.Lock()
+	hooks := append([]ShutdownHook(nil), t.hooks...)
+	t.mu.Unlock()
+
+	var errs []error
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("otelemetry: shutdown hook %d: %w", i, err))
+		}
+	}
+
+	t.Log().Info(ctx, "service stopping", attrs...)
+
+	return errors.Join(append(errs, t.Shutdown(ctx))...)
+}
@@ -0,0 +1,114 @@
+package otelemetry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func newRecordedTelemetry(t *testing.T, recorder *logRecorder) Telemetry {
+	tel, err := New(Config{
+		Service:       Service{Name: "test-service"},
+		Isolated:      true,
+		LoggerOptions: LoggerOptions{ProviderOption: []sdklog.LoggerProviderOption{sdklog.WithProcessor(recorder)}},
+	})
+	require.NoError(t, err)
+	return tel
+}
+
+func TestShutdownOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals cannot be sent to the own process")
+	}
+
+	// keeps the signal from terminating the test before the helper listens
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	recorder := &logRecorder{}
+	tel := newRecordedTelemetry(t, recorder)
+
+	var order []string
+	tel.OnShutdown(func(context.Context) error {
+		order = append(order, "server")
+		return nil
+	})
+	tel.OnShutdown(func(context.Context) error {
+		order = append(order, "database")
+		return nil
+	})
+
+	self, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- tel.ShutdownOnSignal(context.Background(), time.Second, syscall.SIGHUP) }()
+
+	require.Eventually(t, func() bool {
+		select {
+		case err = <-done:
+			return true
+		default:
+			require.NoError(t, self.Signal(syscall.SIGHUP))
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"server", "database"}, order)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.records, 1)
+	record := recorder.records[0]
+	assert.Equal(t, "service stopping", record.Body().AsString())
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		assert.Equal(t, "signal", kv.Key)
+		assert.Equal(t, syscall.SIGHUP.String(), kv.Value.AsString())
+		return true
+	})
+	assert.Equal(t, 1, record.AttributesLen())
+}
+
+func TestShutdownOnSignalContextDone(t *testing.T) {
+	recorder := &logRecorder{}
+	tel := newRecordedTelemetry(t, recorder)
+
+	var deadline time.Time
+	tel.OnShutdown(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return errors.New("server busy")
+	})
+	tel.OnShutdown(func(ctx context.Context) error {
+		// the log record is emitted after the hooks
+		tel.Log().Info(ctx, "closing database")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err := tel.ShutdownOnSignal(ctx, time.Minute)
+	assert.EqualError(t, err, "otelemetry: shutdown hook 0: server busy")
+	assert.WithinDuration(t, start.Add(time.Minute), deadline, 5*time.Second, "hooks run within the grace period")
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.True(t, recorder.shutdown, "the providers are shut down")
+	require.Len(t, recorder.records, 2)
+	assert.Equal(t, "closing database", recorder.records[0].Body().AsString())
+	assert.Equal(t, "service stopping", recorder.records[1].Body().AsString())
+	assert.Zero(t, recorder.records[1].AttributesLen())
+}
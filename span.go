@@ -36,8 +36,10 @@ type Span interface {
 }
 
 // otelspan is an implementation of the Span interface using OpenTelemetry.
+// Events and the end are stamped with the time of clock when set.
 type otelspan struct {
-	span trace.Span
+	span  trace.Span
+	clock Clock
 }
 
 func (s *otelspan) Span() trace.Span {
@@ -49,7 +51,7 @@ func (s *otelspan) AddEvent(name string, kv ...attribute.KeyValue) {
 		return
 	}
 
-	s.span.AddEvent(name, trace.WithAttributes(kv...))
+	s.span.AddEvent(name, s.eventOpts(trace.WithAttributes(kv...))...)
 }
 
 func (s *otelspan) AddErrorEvent(name string, err error, kv ...attribute.KeyValue) {
@@ -60,7 +62,7 @@ func (s *otelspan) AddErrorEvent(name string, err error, kv ...attribute.KeyValu
 	s.span.SetStatus(codes.Error, err.Error())
 	kv = append(kv, attribute.String("error.message", err.Error()))
 	kv = append(kv, attribute.String("error.type", fmt.Sprintf("%T", err)))
-	s.span.AddEvent(name, trace.WithAttributes(kv...))
+	s.span.AddEvent(name, s.eventOpts(trace.WithAttributes(kv...))...)
 }
 
 func (s *otelspan) SetAttribute(kv ...attribute.KeyValue) {
@@ -69,13 +71,23 @@ func (s *otelspan) SetAttribute(kv ...attribute.KeyValue) {
 
 func (s *otelspan) RecordError(err error, kv ...attribute.KeyValue) {
 	s.span.SetStatus(codes.Error, err.Error())
-	s.span.RecordError(err, trace.WithAttributes(kv...))
+	s.span.RecordError(err, s.eventOpts(trace.WithAttributes(kv...))...)
 }
 
 func (s *otelspan) End(opts ...trace.SpanEndOption) {
+	if s.clock != nil {
+		opts = append([]trace.SpanEndOption{trace.WithTimestamp(s.clock.Now())}, opts...)
+	}
 	s.span.End(opts...)
 }
 
+func (s *otelspan) eventOpts(opts ...trace.EventOption) []trace.EventOption {
+	if s.clock != nil {
+		opts = append([]trace.EventOption{trace.WithTimestamp(s.clock.Now())}, opts...)
+	}
+	return opts
+}
+
 func (s *otelspan) TraceID() string {
 	return s.span.SpanContext().TraceID().String()
 }
@@ -4,8 +4,11 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/metric"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
@@ -16,17 +19,34 @@ import (
 )
 
 // Config holds the configuration for the telemetry setup.
+//
+// Every option slice of TracerOptions, MetricOptions and LoggerOptions is
+// honoured, for the OTLP and the stdout pipelines alike (exporter options
+// only apply to their OTLP exporter). The options New derives from Config,
+// such as the endpoint, TLS, headers, export policy, resource, sampler and
+// batch processor, are applied first and the caller's options after them,
+// so a caller option overrides the default it overlaps and leaves the others
+// in place.
 type Config struct {
 	// Service configuration.
 	Service
 	// Collector configuration.
 	Collector
-	// Flag to enable tracing.
+	// Flag to export traces with OTLP rather than to stdout, unless
+	// TracerOptions.Exporter is set.
 	WithTraces bool
-	// Flag to enable metrics.
+	// Flag to export metrics with OTLP rather than to stdout, unless
+	// MetricOptions.Exporter is set.
 	WithMetrics bool
-	// Flag to enable logging.
+	// Flag to export logs with OTLP rather than to stdout, unless
+	// LoggerOptions.Exporter is set.
 	WithLogs bool
+	// Disabled turns every signal into a no-op (OTEL_SDK_DISABLED).
+	Disabled bool
+	// Isolated keeps the providers and propagator to the returned Telemetry:
+	// New leaves the OpenTelemetry globals untouched, so several instances
+	// can live in one process. Telemetry.SetGlobal installs them explicitly.
+	Isolated bool
 	// Options for resource configuration.
 	ResourceOptions []sdkresource.Option
 	// Options for tracer configuration.
@@ -35,6 +55,16 @@ type Config struct {
 	LoggerOptions LoggerOptions
 	// Options for metric configuration.
 	MetricOptions MetricOptions
+	// Propagators lists the context propagation formats by name, as in
+	// OTEL_PROPAGATORS: "tracecontext", "baggage", "b3" (single header),
+	// "b3multi", "jaeger", "xray", "ottrace" (64-bit trace IDs) or "none".
+	// They are combined in order and used by Inject, Extract and the
+	// helpers of package utils. Defaults to tracecontext and baggage.
+	Propagators []string
+	// Clock stamps the log records, the start, events and end of the spans
+	// started through Trace, and the collection times of the metrics.
+	// Defaults to the system clock, as the SDK.
+	Clock Clock
 }
 
 // Service holds the service-related configuration.
@@ -48,25 +78,110 @@ type Service struct {
 }
 
 // Collector holds the collector-related configuration.
+//
+// The same type is used for the per-signal overrides in TracerOptions,
+// MetricOptions and LoggerOptions, where every field left at its zero value
+// falls back to the shared Config.Collector.
 type Collector struct {
 	Host string
 	Port string
+	// Protocol used to export to the collector. Defaults to ProtocolGRPC.
+	Protocol Protocol
+	// TLS settings. The connection is plaintext when nil.
+	TLS *TLS
+	// Headers sent with every export request.
+	Headers map[string]string
+	// Credentials supplies short-lived headers, such as bearer tokens,
+	// refreshed before they expire.
+	Credentials CredentialsProvider
+	// ExportPolicy sets the compression, timeout and retry of the exporters.
+	ExportPolicy
 }
 
+// Protocol is the OTLP transport used to reach the collector.
+type Protocol string
+
+const (
+	// ProtocolGRPC exports over OTLP/gRPC.
+	ProtocolGRPC Protocol = "grpc"
+	// ProtocolHTTPProtobuf exports protobuf-encoded payloads over OTLP/HTTP.
+	ProtocolHTTPProtobuf Protocol = "http/protobuf"
+	// ProtocolHTTPJSON exports JSON-encoded payloads over OTLP/HTTP.
+	ProtocolHTTPJSON Protocol = "http/json"
+)
+
+// Compression is the compression applied to export requests.
+type Compression string
+
+const (
+	// CompressionNone sends requests uncompressed.
+	CompressionNone Compression = "none"
+	// CompressionGzip compresses requests with gzip.
+	CompressionGzip Compression = "gzip"
+)
+
+// Exporter selects where the telemetry of a signal goes.
+type Exporter string
+
+const (
+	// ExporterOTLP exports to the collector.
+	ExporterOTLP Exporter = "otlp"
+	// ExporterStdout writes to the standard output.
+	ExporterStdout Exporter = "stdout"
+	// ExporterNone disables the signal, whose API becomes a no-op.
+	ExporterNone Exporter = "none"
+	// ExporterMemory keeps the telemetry in memory, see Telemetry.Memory.
+	ExporterMemory Exporter = "memory"
+)
+
 // LoggerOptions holds the options for logger configuration.
 type LoggerOptions struct {
-	// Options for the OTLP log exporter.
+	// Exporter of the logs. Defaults to ExporterOTLP when Config.WithLogs
+	// is set, ExporterStdout otherwise.
+	Exporter Exporter
+	// Collector overrides the shared collector settings for logs.
+	Collector *Collector
+	// Options for the OTLP/gRPC log exporter.
 	ExporterOption []otlploggrpc.Option
-	// Options for the logger provider.
+	// Options for the OTLP/HTTP log exporter.
+	HTTPExporterOption []otlploghttp.Option
+	// URL path of the OTLP/HTTP log endpoint. Defaults to /v1/logs.
+	URLPath string
+	// Options for the logger provider. A processor added here runs next
+	// to the batch processor.
 	ProviderOption []sdklog.LoggerProviderOption
+	// Options for the batch log processor.
+	BatchProcessorOption []sdklog.BatchProcessorOption
 	// Options for the logger.
 	LoggerOption []log.LoggerOption
+	// FatalExitCode is the exit code of Log.Fatal. Defaults to 1.
+	FatalExitCode int
+	// ExitFunc ends the process after Log.Fatal flushed the providers.
+	// Defaults to os.Exit; tests can replace it.
+	ExitFunc func(code int)
 }
 
 // TracerOptions holds the options for tracer configuration.
 type TracerOptions struct {
-	// Options for the OTLP trace client.
+	// Exporter of the traces. Defaults to ExporterOTLP when
+	// Config.WithTraces is set, ExporterStdout otherwise.
+	Exporter Exporter
+	// Collector overrides the shared collector settings for traces.
+	Collector *Collector
+	// Options for the OTLP/gRPC trace client.
 	ClientOption []otlptracegrpc.Option
+	// Options for the OTLP/HTTP trace client.
+	HTTPClientOption []otlptracehttp.Option
+	// URL path of the OTLP/HTTP trace endpoint. Defaults to /v1/traces.
+	URLPath string
+	// Sampler of the tracer provider, OTLP or stdout. Defaults to always on.
+	Sampler *Sampler
+	// TailSampling buffers the spans of each trace to keep or drop the trace
+	// as a whole once it ended. Disabled when nil.
+	TailSampling *TailSampling
+	// IDGenerator of the trace and span IDs, see NewDeterministicIDGenerator
+	// and NewXRayIDGenerator. Defaults to the random generator of the SDK.
+	IDGenerator sdktrace.IDGenerator
 	// Options for the tracer provider.
 	ProviderOption []sdktrace.TracerProviderOption
 	// Options for the batch span processor.
@@ -77,12 +192,22 @@ type TracerOptions struct {
 
 // MetricOptions holds the options for metric configuration.
 type MetricOptions struct {
-	// Options for the OTLP metric exporter.
+	// Exporter of the metrics. Defaults to ExporterOTLP when
+	// Config.WithMetrics is set, ExporterStdout otherwise.
+	Exporter Exporter
+	// Collector overrides the shared collector settings for metrics.
+	Collector *Collector
+	// Options for the OTLP/gRPC metric exporter.
 	ExporterOptions []otlpmetricgrpc.Option
+	// Options for the OTLP/HTTP metric exporter.
+	HTTPExporterOptions []otlpmetrichttp.Option
+	// URL path of the OTLP/HTTP metric endpoint. Defaults to /v1/metrics.
+	URLPath string
 	// Options for the metric provider.
 	ProviderOptions []sdkmetric.Option
 	// Options for the meter.
 	MeterOptions []metric.MeterOption
-
+	// Interval of the periodic reader. Defaults to 5 seconds for OTLP and
+	// 1 minute for stdout.
 	PeriodicInterval time.Duration
 }
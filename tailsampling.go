@@ -0,0 +1,402 @@
+package otelemetry
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tail sampling defaults.
+const (
+	defaultTailDecisionWait     = 10 * time.Second
+	defaultTailMaxTraces        = 10000
+	defaultTailMaxSpansPerTrace = 1000
+)
+
+// TailSampling buffers the ended spans of every trace for a decision window,
+// then exports the whole trace if any of the policies keeps it and drops it
+// otherwise.
+//
+// Only spans sampled by the head Sampler reach the tail sampler, which is
+// meant to be used with the default always on sampler.
+type TailSampling struct {
+	// DecisionWait is how long spans are held after the first span of their
+	// trace ended. Defaults to 10 seconds.
+	DecisionWait time.Duration
+	// MaxTraces bounds the traces held in memory. When it is reached, the
+	// oldest trace is decided before the end of its window. Defaults to 10000.
+	MaxTraces int
+	// MaxSpansPerTrace bounds the spans held per trace; further spans of the
+	// trace are dropped. Defaults to 1000.
+	MaxSpansPerTrace int
+	// Policies keeping a trace when any of them matches. Every trace is kept
+	// when there are none.
+	Policies []TailSamplingPolicy
+}
+
+// TailPolicyType selects what a TailSamplingPolicy matches.
+type TailPolicyType string
+
+const (
+	// TailPolicyStatusError keeps the traces with a span whose status is error.
+	TailPolicyStatusError TailPolicyType = "status_error"
+	// TailPolicyLatency keeps the traces lasting at least Latency, from the
+	// first span start to the last span end.
+	TailPolicyLatency TailPolicyType = "latency"
+	// TailPolicyAttribute keeps the traces with a span carrying all Attributes.
+	TailPolicyAttribute TailPolicyType = "attribute"
+	// TailPolicyProbabilistic keeps Ratio of the traces, by trace ID.
+	TailPolicyProbabilistic TailPolicyType = "probabilistic"
+)
+
+// TailSamplingPolicy is a condition keeping a trace.
+type TailSamplingPolicy struct {
+	Type TailPolicyType
+	// Latency threshold of TailPolicyLatency.
+	Latency time.Duration
+	// Attributes of TailPolicyAttribute, compared as strings.
+	Attributes map[string]string
+	// Ratio of TailPolicyProbabilistic, in [0, 1].
+	Ratio float64
+}
+
+func (t *TailSampling) validate() []error {
+	if t == nil {
+		return nil
+	}
+
+	var errs []error
+
+	if t.DecisionWait < 0 || t.MaxTraces < 0 || t.MaxSpansPerTrace < 0 {
+		errs = append(errs, errors.New("tail sampling limits must not be negative"))
+	}
+
+	for i, p := range t.Policies {
+		switch p.Type {
+		case TailPolicyStatusError:
+		case TailPolicyLatency:
+			if p.Latency <= 0 {
+				errs = append(errs, fmt.Errorf("tail sampling policy %d: latency must be positive", i))
+			}
+		case TailPolicyAttribute:
+			if len(p.Attributes) == 0 {
+				errs = append(errs, fmt.Errorf("tail sampling policy %d: attributes are required", i))
+			}
+		case TailPolicyProbabilistic:
+			if p.Ratio < 0 || p.Ratio > 1 {
+				errs = append(errs, fmt.Errorf("tail sampling policy %d: ratio %v out of range [0, 1]", i, p.Ratio))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("tail sampling policy %d: unsupported type %q", i, p.Type))
+		}
+	}
+
+	return errs
+}
+
+// tailSamplingStats counts the decisions of a tailSamplingProcessor.
+type tailSamplingStats struct {
+	tracesKept    atomic.Int64
+	tracesDropped atomic.Int64
+	// tracesEvicted were decided early because MaxTraces was reached.
+	tracesEvicted atomic.Int64
+	// spansDropped belong to dropped traces.
+	spansDropped atomic.Int64
+	// spansOverflow exceeded MaxSpansPerTrace.
+	spansOverflow atomic.Int64
+}
+
+// pendingTrace holds the spans of a trace waiting for its decision.
+type pendingTrace struct {
+	id       trace.TraceID
+	spans    []sdktrace.ReadOnlySpan
+	deadline time.Time
+}
+
+// tailSamplingProcessor is the span processor implementing TailSampling. It
+// forwards the spans of the kept traces to next.
+type tailSamplingProcessor struct {
+	cfg   TailSampling
+	next  sdktrace.SpanProcessor
+	now   func() time.Time
+	stats tailSamplingStats
+
+	mu      sync.Mutex
+	pending map[trace.TraceID]*list.Element
+	order   *list.List // of *pendingTrace, oldest first
+	// decided remembers recent decisions for the spans ending late.
+	decided map[trace.TraceID]decision
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type decision struct {
+	keep    bool
+	expires time.Time
+}
+
+func newTailSamplingProcessor(cfg TailSampling, next sdktrace.SpanProcessor) *tailSamplingProcessor {
+	if cfg.DecisionWait == 0 {
+		cfg.DecisionWait = defaultTailDecisionWait
+	}
+	if cfg.MaxTraces == 0 {
+		cfg.MaxTraces = defaultTailMaxTraces
+	}
+	if cfg.MaxSpansPerTrace == 0 {
+		cfg.MaxSpansPerTrace = defaultTailMaxSpansPerTrace
+	}
+
+	p := &tailSamplingProcessor{
+		cfg:     cfg,
+		next:    next,
+		now:     time.Now,
+		pending: make(map[trace.TraceID]*list.Element),
+		order:   list.New(),
+		decided: make(map[trace.TraceID]decision),
+		done:    make(chan struct{}),
+	}
+
+	tick := max(cfg.DecisionWait/10, 10*time.Millisecond)
+	p.wg.Add(1)
+	go p.run(tick)
+
+	return p
+}
+
+func (p *tailSamplingProcessor) run(tick time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.decideExpired(p.now())
+		}
+	}
+}
+
+func (p *tailSamplingProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *tailSamplingProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	id := span.SpanContext().TraceID()
+	now := p.now()
+
+	p.mu.Lock()
+
+	if d, ok := p.decided[id]; ok {
+		p.mu.Unlock()
+		if d.keep {
+			p.next.OnEnd(span)
+		} else {
+			p.stats.spansDropped.Add(1)
+		}
+		return
+	}
+
+	var forward []sdktrace.ReadOnlySpan
+	el, ok := p.pending[id]
+	if !ok {
+		if p.order.Len() >= p.cfg.MaxTraces {
+			p.stats.tracesEvicted.Add(1)
+			forward = p.decide(p.order.Front(), now)
+		}
+		el = p.order.PushBack(&pendingTrace{id: id, deadline: now.Add(p.cfg.DecisionWait)})
+		p.pending[id] = el
+	}
+
+	t := el.Value.(*pendingTrace)
+	if len(t.spans) < p.cfg.MaxSpansPerTrace {
+		t.spans = append(t.spans, span)
+	} else {
+		p.stats.spansOverflow.Add(1)
+	}
+
+	p.mu.Unlock()
+
+	for _, s := range forward {
+		p.next.OnEnd(s)
+	}
+}
+
+// decideExpired decides the traces whose window ended by now.
+func (p *tailSamplingProcessor) decideExpired(now time.Time) {
+	p.decideWhile(now, func(t *pendingTrace) bool { return !t.deadline.After(now) })
+}
+
+// decideAll decides every pending trace.
+func (p *tailSamplingProcessor) decideAll() {
+	p.decideWhile(p.now(), func(*pendingTrace) bool { return true })
+}
+
+func (p *tailSamplingProcessor) decideWhile(now time.Time, cond func(*pendingTrace) bool) {
+	var forward []sdktrace.ReadOnlySpan
+
+	p.mu.Lock()
+	for el := p.order.Front(); el != nil && cond(el.Value.(*pendingTrace)); el = p.order.Front() {
+		forward = append(forward, p.decide(el, now)...)
+	}
+	for id, d := range p.decided {
+		if d.expires.Before(now) {
+			delete(p.decided, id)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, s := range forward {
+		p.next.OnEnd(s)
+	}
+}
+
+// decide removes the trace of el from the pending ones and returns its spans
+// if it is kept. p.mu must be held.
+func (p *tailSamplingProcessor) decide(el *list.Element, now time.Time) []sdktrace.ReadOnlySpan {
+	t := p.order.Remove(el).(*pendingTrace)
+	delete(p.pending, t.id)
+
+	keep := p.keep(t)
+	if len(p.decided) < p.cfg.MaxTraces {
+		p.decided[t.id] = decision{keep: keep, expires: now.Add(p.cfg.DecisionWait)}
+	}
+
+	if !keep {
+		p.stats.tracesDropped.Add(1)
+		p.stats.spansDropped.Add(int64(len(t.spans)))
+		return nil
+	}
+
+	p.stats.tracesKept.Add(1)
+	return t.spans
+}
+
+func (p *tailSamplingProcessor) keep(t *pendingTrace) bool {
+	if len(p.cfg.Policies) == 0 {
+		return true
+	}
+
+	for _, policy := range p.cfg.Policies {
+		if policy.matches(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (policy TailSamplingPolicy) matches(t *pendingTrace) bool {
+	switch policy.Type {
+	case TailPolicyStatusError:
+		for _, s := range t.spans {
+			if s.Status().Code == codes.Error {
+				return true
+			}
+		}
+	case TailPolicyLatency:
+		var start, end time.Time
+		for _, s := range t.spans {
+			if start.IsZero() || s.StartTime().Before(start) {
+				start = s.StartTime()
+			}
+			if s.EndTime().After(end) {
+				end = s.EndTime()
+			}
+		}
+		return end.Sub(start) >= policy.Latency
+	case TailPolicyAttribute:
+		for _, s := range t.spans {
+			if hasAttributes(s.Attributes(), policy.Attributes) {
+				return true
+			}
+		}
+	case TailPolicyProbabilistic:
+		return sdktrace.TraceIDRatioBased(policy.Ratio).ShouldSample(
+			sdktrace.SamplingParameters{TraceID: t.id}).Decision == sdktrace.RecordAndSample
+	}
+
+	return false
+}
+
+// hasAttributes reports whether attrs holds every key of want with its value.
+func hasAttributes(attrs []attribute.KeyValue, want map[string]string) bool {
+	for key, value := range want {
+		found := false
+		for _, attr := range attrs {
+			if string(attr.Key) == key {
+				found = attr.Value.Emit() == value
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ForceFlush decides every pending trace, without waiting for the end of
+// their window, and flushes the kept ones.
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	p.decideAll()
+	return p.next.ForceFlush(ctx)
+}
+
+// Shutdown decides every pending trace and shuts down the next processor.
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.done) })
+	p.wg.Wait()
+
+	p.decideAll()
+	return p.next.Shutdown(ctx)
+}
+
+// registerMetrics reports the decision counters as observable counters.
+func (p *tailSamplingProcessor) registerMetrics(meter metric.Meter) error {
+	traces, err := meter.Int64ObservableCounter("otelemetry.tail_sampling.traces",
+		metric.WithDescription("Traces decided by the tail sampler, by decision."))
+	if err != nil {
+		return err
+	}
+	evicted, err := meter.Int64ObservableCounter("otelemetry.tail_sampling.traces.evicted",
+		metric.WithDescription("Traces decided before the end of their window because the trace limit was reached."))
+	if err != nil {
+		return err
+	}
+	spans, err := meter.Int64ObservableCounter("otelemetry.tail_sampling.spans.dropped",
+		metric.WithDescription("Spans dropped by the tail sampler, by reason."))
+	if err != nil {
+		return err
+	}
+
+	var (
+		kept     = metric.WithAttributes(attribute.String("decision", "kept"))
+		dropped  = metric.WithAttributes(attribute.String("decision", "dropped"))
+		policy   = metric.WithAttributes(attribute.String("reason", "policy"))
+		overflow = metric.WithAttributes(attribute.String("reason", "span_limit"))
+	)
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(traces, p.stats.tracesKept.Load(), kept)
+		o.ObserveInt64(traces, p.stats.tracesDropped.Load(), dropped)
+		o.ObserveInt64(evicted, p.stats.tracesEvicted.Load())
+		o.ObserveInt64(spans, p.stats.spansDropped.Load(), policy)
+		o.ObserveInt64(spans, p.stats.spansOverflow.Load(), overflow)
+		return nil
+	}, traces, evicted, spans)
+
+	return err
+}
@@ -0,0 +1,214 @@
+package otelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTailSampler returns a tracer whose spans go through a tail sampling
+// processor forwarding the kept spans to the returned recorder.
+func newTailSampler(t *testing.T, cfg TailSampling) (trace.Tracer, *tailSamplingProcessor, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tail := newTailSamplingProcessor(cfg, recorder)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tail))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return provider.Tracer("test"), tail, recorder
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func TestTailSamplingPolicies(t *testing.T) {
+	tracer, tail, recorder := newTailSampler(t, TailSampling{
+		Policies: []TailSamplingPolicy{
+			{Type: TailPolicyStatusError},
+			{Type: TailPolicyLatency, Latency: 500 * time.Millisecond},
+			{Type: TailPolicyAttribute, Attributes: map[string]string{"tenant": "vip"}},
+		},
+	})
+	ctx := context.Background()
+	start := time.Now()
+
+	// fast and successful: dropped
+	ctx1, root := tracer.Start(ctx, "ok")
+	_, child := tracer.Start(ctx1, "ok-child")
+	child.End()
+	root.End()
+
+	// error in a child: kept as a whole
+	ctx2, root := tracer.Start(ctx, "error")
+	_, child = tracer.Start(ctx2, "error-child")
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	root.End()
+
+	// slow: kept
+	_, root = tracer.Start(ctx, "slow", trace.WithTimestamp(start))
+	root.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	// matching attribute: kept
+	_, root = tracer.Start(ctx, "vip", trace.WithAttributes(attribute.String("tenant", "vip")))
+	root.End()
+
+	assert.Empty(t, recorder.Ended(), "spans are held until the decision")
+	require.NoError(t, tail.ForceFlush(ctx))
+
+	assert.ElementsMatch(t, []string{"error-child", "error", "slow", "vip"}, spanNames(recorder))
+	assert.Equal(t, int64(3), tail.stats.tracesKept.Load())
+	assert.Equal(t, int64(1), tail.stats.tracesDropped.Load())
+	assert.Equal(t, int64(2), tail.stats.spansDropped.Load())
+}
+
+func TestTailSamplingProbabilistic(t *testing.T) {
+	for _, ratio := range []float64{0, 1} {
+		tracer, tail, recorder := newTailSampler(t, TailSampling{
+			Policies: []TailSamplingPolicy{{Type: TailPolicyProbabilistic, Ratio: ratio}},
+		})
+
+		for i := 0; i < 10; i++ {
+			_, span := tracer.Start(context.Background(), "span")
+			span.End()
+		}
+		require.NoError(t, tail.ForceFlush(context.Background()))
+
+		assert.Len(t, recorder.Ended(), int(ratio*10))
+	}
+}
+
+func TestTailSamplingMemoryBounds(t *testing.T) {
+	tracer, tail, recorder := newTailSampler(t, TailSampling{MaxTraces: 2, MaxSpansPerTrace: 2})
+	ctx := context.Background()
+
+	ctx1, first := tracer.Start(ctx, "first")
+	for i := 0; i < 2; i++ {
+		_, child := tracer.Start(ctx1, "child")
+		child.End()
+	}
+	first.End() // over MaxSpansPerTrace
+
+	_, second := tracer.Start(ctx, "second")
+	second.End()
+
+	// over MaxTraces: the first trace is decided early
+	_, third := tracer.Start(ctx, "third")
+	third.End()
+
+	assert.Equal(t, []string{"child", "child"}, spanNames(recorder))
+	assert.Equal(t, int64(1), tail.stats.tracesEvicted.Load())
+	assert.Equal(t, int64(1), tail.stats.spansOverflow.Load())
+
+	require.NoError(t, tail.ForceFlush(ctx))
+	assert.Equal(t, []string{"child", "child", "second", "third"}, spanNames(recorder))
+}
+
+func TestTailSamplingLateSpans(t *testing.T) {
+	tracer, tail, recorder := newTailSampler(t, TailSampling{
+		Policies: []TailSamplingPolicy{{Type: TailPolicyStatusError}},
+	})
+	ctx := context.Background()
+
+	keptCtx, kept := tracer.Start(ctx, "kept")
+	_, keptChild := tracer.Start(keptCtx, "kept-late")
+	kept.SetStatus(codes.Error, "boom")
+	kept.End()
+
+	droppedCtx, dropped := tracer.Start(ctx, "dropped")
+	_, droppedChild := tracer.Start(droppedCtx, "dropped-late")
+	dropped.End()
+
+	require.NoError(t, tail.ForceFlush(ctx))
+
+	// children ending after the decision follow it
+	keptChild.End()
+	droppedChild.End()
+
+	assert.Equal(t, []string{"kept", "kept-late"}, spanNames(recorder))
+	assert.Equal(t, int64(2), tail.stats.spansDropped.Load())
+}
+
+func TestTailSamplingDecisionWait(t *testing.T) {
+	tracer, _, recorder := newTailSampler(t, TailSampling{DecisionWait: 50 * time.Millisecond})
+
+	_, span := tracer.Start(context.Background(), "span")
+	span.End()
+
+	assert.Empty(t, recorder.Ended())
+	assert.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestTailSamplingWithNew(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+
+	tel, err := New(Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{
+			TailSampling: &TailSampling{Policies: []TailSamplingPolicy{{Type: TailPolicyStatusError}}},
+		},
+		MetricOptions: MetricOptions{ProviderOptions: []sdkmetric.Option{sdkmetric.WithReader(reader)}},
+	})
+	require.NoError(t, err)
+
+	_, span := tel.Trace().StartSpan(context.Background(), "span")
+	span.End()
+	require.NoError(t, tel.(*telemetry).tracerProvider.ForceFlush(context.Background()))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.NoError(t, tel.Shutdown(context.Background()))
+
+	counts := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != instrumentationName {
+			continue
+		}
+		for _, m := range sm.Metrics {
+			if m.Name != "otelemetry.tail_sampling.traces" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				decision, _ := dp.Attributes.Value("decision")
+				counts[decision.AsString()] = dp.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{"kept": 0, "dropped": 1}, counts)
+}
+
+func TestTailSamplingValidation(t *testing.T) {
+	err := Config{
+		Service: Service{Name: "test-service"},
+		TracerOptions: TracerOptions{TailSampling: &TailSampling{
+			MaxTraces: -1,
+			Policies: []TailSamplingPolicy{
+				{Type: TailPolicyLatency},
+				{Type: TailPolicyAttribute},
+				{Type: TailPolicyProbabilistic, Ratio: 2},
+				{Type: "sometimes"},
+			},
+		}},
+	}.Validate()
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "tail sampling limits must not be negative")
+	assert.ErrorContains(t, err, "tail sampling policy 0: latency must be positive")
+	assert.ErrorContains(t, err, "tail sampling policy 1: attributes are required")
+	assert.ErrorContains(t, err, "tail sampling policy 2: ratio 2 out of range [0, 1]")
+	assert.ErrorContains(t, err, `tail sampling policy 3: unsupported type "sometimes"`)
+}
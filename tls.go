@@ -0,0 +1,209 @@
+package otelemetry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// TLS holds the TLS settings used to connect to the collector.
+//
+// Certificate files are checked for changes on every new connection and
+// reloaded when modified, so rotated certificates are picked up without a
+// restart. If a reload fails, the previously loaded files keep being used.
+type TLS struct {
+	// CAFile is a PEM bundle used to verify the collector certificate.
+	// Defaults to the system roots.
+	CAFile string
+	// CertFile is the PEM client certificate used for mTLS.
+	CertFile string
+	// KeyFile is the PEM private key of CertFile.
+	KeyFile string
+	// ServerName overrides the host name used to verify the collector certificate.
+	ServerName string
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13. Defaults to TLS 1.2.
+	MinVersion uint16
+	// InsecureSkipVerify disables verification of the collector certificate.
+	// For development only.
+	InsecureSkipVerify bool
+}
+
+func (t *TLS) validate() []error {
+	var errs []error
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, errors.New("tls cert file and key file must be set together"))
+	}
+
+	if t.InsecureSkipVerify && t.CAFile != "" {
+		errs = append(errs, errors.New("tls ca file cannot be combined with insecure skip verify"))
+	}
+
+	switch t.MinVersion {
+	case 0, tls.VersionTLS12, tls.VersionTLS13:
+	case tls.VersionTLS10, tls.VersionTLS11:
+		errs = append(errs, fmt.Errorf("tls min version %s is not allowed, use TLS 1.2 or later", tls.VersionName(t.MinVersion)))
+	default:
+		errs = append(errs, fmt.Errorf("unknown tls min version %#x", t.MinVersion))
+	}
+
+	return errs
+}
+
+// newTLSConfig builds the client TLS configuration, or returns nil for an
+// insecure (plaintext) connection when t is nil. The collector certificate is
+// verified against t.ServerName, or host when it is not set; an IP address
+// host is matched against the IP SANs of the certificate.
+func newTLSConfig(t *TLS, host string) (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	serverName := t.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	cfg := &tls.Config{
+		ServerName:         serverName,
+		MinVersion:         t.MinVersion,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if t.CAFile == "" && t.CertFile == "" {
+		return cfg, nil
+	}
+
+	r := &certReloader{caFile: t.CAFile, certFile: t.CertFile, keyFile: t.KeyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	if t.CertFile != "" {
+		cfg.GetClientCertificate = r.clientCertificate
+	}
+
+	if t.CAFile != "" {
+		// The standard verification cannot use a pool that changes after the
+		// config is created, so it is disabled and done in VerifyConnection
+		// against the current pool instead.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verifyConnection(cs, serverName)
+		}
+	}
+
+	return cfg, nil
+}
+
+// certReloader holds the client certificate and CA pool loaded from disk and
+// reloads them when the files change.
+type certReloader struct {
+	caFile   string
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	modTime map[string]time.Time
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// reload reads the files again if any of them changed since the last load.
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime := make(map[string]time.Time)
+	changed := r.modTime == nil
+	for _, file := range []string{r.caFile, r.certFile, r.keyFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		modTime[file] = info.ModTime()
+		if !info.ModTime().Equal(r.modTime[file]) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	var (
+		cert *tls.Certificate
+		pool *x509.CertPool
+	)
+
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("tls: loading client certificate: %w", err)
+		}
+		cert = &c
+	}
+
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tls: no certificates found in %s", r.caFile)
+		}
+	}
+
+	r.cert, r.pool, r.modTime = cert, pool, modTime
+	return nil
+}
+
+// current reloads changed files, keeping the previous ones on failure.
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	if err := r.reload(); err != nil {
+		otel.Handle(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.pool
+}
+
+func (r *certReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// verifyConnection verifies the collector certificate chain against the
+// current CA pool and the certificate names against serverName.
+func (r *certReloader) verifyConnection(cs tls.ConnectionState, serverName string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: collector presented no certificate")
+	}
+
+	_, pool := r.current()
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,253 @@
+package otelemetry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by the CA, valid for the
+// given IP addresses and DNS names, or 127.0.0.1 when none are given.
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage, hosts ...string) ([]byte, []byte) {
+	t.Helper()
+
+	if len(hosts) == 0 {
+		hosts = []string{"127.0.0.1"}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestNewWithMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "collector", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	dir := t.TempDir()
+	tlsCfg := &TLS{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client-key.pem"),
+	}
+	writeFile(t, tlsCfg.CAFile, ca.pem, time.Now())
+	writeFile(t, tlsCfg.CertFile, clientCert, time.Now())
+	writeFile(t, tlsCfg.KeyFile, clientKey, time.Now())
+
+	var (
+		mu      sync.Mutex
+		clients []string
+	)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		clients = append(clients, r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	tel, err := New(Config{
+		Service:    Service{Name: "test-service"},
+		Collector:  Collector{Host: host, Port: port, Protocol: ProtocolHTTPProtobuf, TLS: tlsCfg},
+		WithTraces: true,
+	})
+	require.NoError(t, err)
+
+	_, span := tel.Trace().StartSpan(context.Background(), "tls-span")
+	span.End()
+	require.NoError(t, tel.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, clients, "client")
+}
+
+func TestTLSConfigReloadsCertificates(t *testing.T) {
+	ca := newTestCA(t)
+	first, firstKey := ca.issue(t, "first", x509.ExtKeyUsageClientAuth)
+	second, secondKey := ca.issue(t, "second", x509.ExtKeyUsageClientAuth)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	writeFile(t, certFile, first, time.Now().Add(-time.Minute))
+	writeFile(t, keyFile, firstKey, time.Now().Add(-time.Minute))
+
+	cfg, err := newTLSConfig(&TLS{CertFile: certFile, KeyFile: keyFile}, "localhost")
+	require.NoError(t, err)
+
+	commonName := func() string {
+		cert, err := cfg.GetClientCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+
+	assert.Equal(t, "first", commonName())
+
+	// a broken rotation keeps the previous certificate
+	writeFile(t, keyFile, []byte("garbage"), time.Now())
+	assert.Equal(t, "first", commonName())
+
+	writeFile(t, certFile, second, time.Now().Add(time.Minute))
+	writeFile(t, keyFile, secondKey, time.Now().Add(time.Minute))
+	assert.Equal(t, "second", commonName())
+}
+
+func TestTLSConfigVerifiesCollectorName(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.pem, time.Now())
+
+	tests := []struct {
+		name       string
+		hosts      []string
+		serverName string
+		wantErr    bool
+	}{
+		{name: "ip san", hosts: []string{"127.0.0.1"}},
+		{name: "other ip san", hosts: []string{"10.0.0.1"}, wantErr: true},
+		{name: "dns san without server name", hosts: []string{"collector.internal"}, wantErr: true},
+		{name: "server name", hosts: []string{"collector.internal"}, serverName: "collector.internal"},
+		{name: "wrong server name", hosts: []string{"collector.internal"}, serverName: "other.internal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverCert, serverKey := ca.issue(t, "collector", x509.ExtKeyUsageServerAuth, tt.hosts...)
+			cert, err := tls.X509KeyPair(serverCert, serverKey)
+			require.NoError(t, err)
+
+			ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+			require.NoError(t, err)
+			t.Cleanup(func() { ln.Close() })
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+
+			host, _, err := net.SplitHostPort(ln.Addr().String())
+			require.NoError(t, err)
+			cfg, err := newTLSConfig(&TLS{CAFile: caFile, ServerName: tt.serverName}, host)
+			require.NoError(t, err)
+
+			conn, err := tls.Dial("tcp", ln.Addr().String(), cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, conn.Close())
+		})
+	}
+}
+
+func TestTLSValidation(t *testing.T) {
+	err := Config{
+		Service:    Service{Name: "test-service"},
+		Collector:  Collector{Host: "localhost", Port: "4317", TLS: &TLS{CertFile: "client.pem", CAFile: "ca.pem", InsecureSkipVerify: true, MinVersion: tls.VersionTLS10}},
+		WithTraces: true,
+	}.Validate()
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "cert file and key file must be set together")
+	assert.ErrorContains(t, err, "cannot be combined with insecure skip verify")
+	assert.ErrorContains(t, err, "TLS 1.0 is not allowed")
+}
@@ -2,14 +2,18 @@ package otelemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Trace interface provides methods for tracing operations.
@@ -31,6 +35,7 @@ type Trace interface {
 // oteltrace is an implementation of the Trace interface using OpenTelemetry.
 type oteltrace struct {
 	trace trace.Tracer
+	clock Clock
 }
 
 func (t *oteltrace) Trace() trace.Tracer {
@@ -38,8 +43,11 @@ func (t *oteltrace) Trace() trace.Tracer {
 }
 
 func (t *oteltrace) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, Span) {
+	if t.clock != nil {
+		opts = append([]trace.SpanStartOption{trace.WithTimestamp(t.clock.Now())}, opts...)
+	}
 	ctx, span := t.trace.Start(ctx, name, opts...)
-	return ctx, &otelspan{span: span}
+	return ctx, &otelspan{span: span, clock: t.clock}
 }
 
 func (t *oteltrace) SpanFromContext(ctx context.Context) Span {
@@ -47,7 +55,7 @@ func (t *oteltrace) SpanFromContext(ctx context.Context) Span {
 	if span == nil {
 		return nil
 	}
-	return &otelspan{span: span}
+	return &otelspan{span: span, clock: t.clock}
 }
 
 func (t *oteltrace) ContextWithSpan(ctx context.Context, span trace.Span) context.Context {
@@ -58,46 +66,135 @@ func (t *oteltrace) ContextWithRemoteSpanContext(ctx context.Context, span trace
 	return trace.ContextWithRemoteSpanContext(ctx, span.SpanContext())
 }
 
-func newTraceProvider(ctx context.Context, otelAgentAddr string, res *sdkresource.Resource, opts TracerOptions) (*sdktrace.TracerProvider, error) {
-	client := otlptracegrpc.NewClient(traceClientOpts(otelAgentAddr, opts.ClientOption...)...)
-	exporter, err := otlptrace.New(ctx, client)
+// newTraceProvider returns the OTLP trace provider and its tail sampling
+// processor, nil unless opts.TailSampling is set.
+func newTraceProvider(ctx context.Context, collector Collector, res *sdkresource.Resource, opts TracerOptions) (*sdktrace.TracerProvider, *tailSamplingProcessor, error) {
+	exporter, err := newTraceExporter(ctx, collector, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	providerOpts, tail := traceProviderOpts(sdktrace.NewBatchSpanProcessor(exporter, opts.BatchSpanProcessorOption...), res, opts)
+	return sdktrace.NewTracerProvider(providerOpts...), tail, nil
+}
+
+func newStdoutTraceProvider(res *sdkresource.Resource, opts TracerOptions) (*sdktrace.TracerProvider, *tailSamplingProcessor, error) {
+	exporter, err := stdouttrace.New( /*stdouttrace.WithPrettyPrint()*/ )
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating stdout exporter: %w", err)
 	}
 
-	bsp := sdktrace.NewBatchSpanProcessor(exporter, opts.BatchSpanProcessorOption...)
-	provider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+	providerOpts, tail := traceProviderOpts(sdktrace.NewBatchSpanProcessor(exporter, opts.BatchSpanProcessorOption...), res, opts)
+	return sdktrace.NewTracerProvider(providerOpts...), tail, nil
+}
+
+// newMemoryTraceProvider exports every span to memory as soon as it ends.
+func newMemoryTraceProvider(memory *Memory, res *sdkresource.Resource, opts TracerOptions) (*sdktrace.TracerProvider, *tailSamplingProcessor, error) {
+	providerOpts, tail := traceProviderOpts(sdktrace.NewSimpleSpanProcessor(memory.spans), res, opts)
+	return sdktrace.NewTracerProvider(providerOpts...), tail, nil
+}
+
+func traceProviderOpts(processor sdktrace.SpanProcessor, res *sdkresource.Resource, opts TracerOptions) ([]sdktrace.TracerProviderOption, *tailSamplingProcessor) {
+	var tail *tailSamplingProcessor
+	if opts.TailSampling != nil {
+		tail = newTailSamplingProcessor(*opts.TailSampling, processor)
+		processor = tail
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(opts.Sampler.sampler()),
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(bsp),
-	)
+		sdktrace.WithSpanProcessor(processor),
+	}
+	if opts.IDGenerator != nil {
+		options = append(options, sdktrace.WithIDGenerator(opts.IDGenerator))
+	}
 
-	return provider, nil
+	return withDefaults(options, opts.ProviderOption...), tail
 }
 
-func newStdoutTraceProvider(res *sdkresource.Resource) (*sdktrace.TracerProvider, error) {
-	exporter, err := stdouttrace.New( /*stdouttrace.WithPrettyPrint()*/)
+func newTraceExporter(ctx context.Context, collector Collector, opts TracerOptions) (*otlptrace.Exporter, error) {
+	tlsCfg, err := newTLSConfig(collector.TLS, collector.Host)
 	if err != nil {
-		return nil, fmt.Errorf("creating stdout exporter: %w", err)
+		return nil, err
 	}
 
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-	)
+	if collector.Protocol.isHTTP() {
+		return otlptracehttp.New(ctx, traceHTTPClientOpts(collector, tlsCfg, opts.URLPath, opts.HTTPClientOption...)...)
+	}
+
+	client := otlptracegrpc.NewClient(traceClientOpts(collector, tlsCfg, opts.ClientOption...)...)
+	return otlptrace.New(ctx, client)
+}
+
+func traceHTTPClientOpts(collector Collector, tlsCfg *tls.Config, urlPath string, opts ...otlptracehttp.Option) []otlptracehttp.Option {
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlptracehttp.WithInsecure())
+	} else {
+		options = append(options, otlptracehttp.WithTLSClientConfig(tlsCfg))
+	}
+
+	if urlPath != "" {
+		options = append(options, otlptracehttp.WithURLPath(urlPath))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(collector.Headers))
+	}
+
+	if client := newHTTPClient(collector, tlsCfg, SignalTraces); client != nil {
+		options = append(options, otlptracehttp.WithHTTPClient(client))
+	}
+
+	if collector.Timeout > 0 {
+		options = append(options, otlptracehttp.WithTimeout(collector.Timeout))
+	}
 
-	return tracerProvider, nil
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+
+	if collector.Retry != nil {
+		options = append(options, otlptracehttp.WithRetry(otlptracehttp.RetryConfig(collector.Retry.config())))
+	}
+
+	return withDefaults(options, opts...)
 }
 
-func traceClientOpts(otelAgentAddr string, opts ...otlptracegrpc.Option) []otlptracegrpc.Option {
+func traceClientOpts(collector Collector, tlsCfg *tls.Config, opts ...otlptracegrpc.Option) []otlptracegrpc.Option {
 	options := []otlptracegrpc.Option{
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(otelAgentAddr),
+		otlptracegrpc.WithEndpoint(collector.endpoint()),
+	}
+
+	if tlsCfg == nil {
+		options = append(options, otlptracegrpc.WithInsecure())
+	} else {
+		options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+	}
+
+	if len(collector.Headers) > 0 {
+		options = append(options, otlptracegrpc.WithHeaders(collector.Headers))
+	}
+
+	if collector.Credentials != nil {
+		options = append(options, otlptracegrpc.WithDialOption(grpc.WithPerRPCCredentials(newCredentialsPerRPC(collector.Credentials))))
+	}
+
+	if collector.Timeout > 0 {
+		options = append(options, otlptracegrpc.WithTimeout(collector.Timeout))
+	}
+
+	if collector.Compression == CompressionGzip {
+		options = append(options, otlptracegrpc.WithCompressor(string(CompressionGzip)))
 	}
 
-	if len(opts) > 0 {
-		options = append(options, opts...)
+	if collector.Retry != nil {
+		options = append(options, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(collector.Retry.config())))
 	}
 
-	return options
+	return withDefaults(options, opts...)
 }
@@ -0,0 +1,119 @@
+package otelemetry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otTraceStateKey is the tracestate entry reserved for OpenTelemetry, holding
+// ;-separated key:value pairs, e.g. "ot=th:c;rv:0123456789abcd".
+const otTraceStateKey = "ot"
+
+// otValue returns the value of key in the ot entry of ts.
+func otValue(ts trace.TraceState, key string) (string, bool) {
+	for _, pair := range strings.Split(ts.Get(otTraceStateKey), ";") {
+		if k, v, ok := strings.Cut(pair, ":"); ok && k == key {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// withOTValue returns ts with key set to value in its ot entry, keeping the
+// other keys of the entry.
+func withOTValue(ts trace.TraceState, key, value string) trace.TraceState {
+	pairs := append([]string{key + ":" + value}, otPairsWithout(ts, key)...)
+
+	updated, err := ts.Insert(otTraceStateKey, strings.Join(pairs, ";"))
+	if err != nil {
+		return ts
+	}
+
+	return updated
+}
+
+// withoutOTValue returns ts without key in its ot entry, removing the entry
+// when no other key is left.
+func withoutOTValue(ts trace.TraceState, key string) trace.TraceState {
+	if _, ok := otValue(ts, key); !ok {
+		return ts
+	}
+
+	pairs := otPairsWithout(ts, key)
+	if len(pairs) == 0 {
+		return ts.Delete(otTraceStateKey)
+	}
+
+	updated, err := ts.Insert(otTraceStateKey, strings.Join(pairs, ";"))
+	if err != nil {
+		return ts
+	}
+
+	return updated
+}
+
+// otPairsWithout returns the key:value pairs of the ot entry of ts, except key.
+func otPairsWithout(ts trace.TraceState, key string) []string {
+	entry := ts.Get(otTraceStateKey)
+	if entry == "" {
+		return nil
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(entry, ";") {
+		if k, _, _ := strings.Cut(pair, ":"); k != key {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+// maxThreshold is the number of distinct 56-bit thresholds and randomness
+// values of OpenTelemetry consistent probability sampling.
+const maxThreshold = 1 << 56
+
+// threshold returns the rejection threshold of the probability p: a span is
+// sampled when its randomness value is at least the threshold.
+func threshold(p float64) uint64 {
+	// rounding p rather than 1-p keeps the precision of small probabilities
+	return maxThreshold - uint64(math.Round(p*maxThreshold))
+}
+
+// encodeThreshold returns the th value of the probability p: the rejection
+// threshold (1-p)*2^56 as 14 hex digits with the trailing zeros removed.
+func encodeThreshold(p float64) string {
+	t := threshold(p)
+	if t >= maxThreshold {
+		t = maxThreshold - 1
+	}
+	if t == 0 {
+		return "0"
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%014x", t), "0")
+}
+
+// encodeRandomness returns the rv value of the randomness r, 14 hex digits.
+func encodeRandomness(r uint64) string {
+	return fmt.Sprintf("%014x", r)
+}
+
+// randomness returns the randomness value of a trace: the rv value of ts
+// when valid, otherwise the 56 least significant bits of the trace ID.
+// The bool reports whether it came from ts.
+func randomness(ts trace.TraceState, traceID trace.TraceID) (uint64, bool) {
+	if rv, ok := otValue(ts, "rv"); ok && len(rv) == 14 {
+		if r, err := strconv.ParseUint(rv, 16, 64); err == nil {
+			return r, true
+		}
+	}
+
+	return binary.BigEndian.Uint64(traceID[8:]) & (maxThreshold - 1), false
+}
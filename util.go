@@ -7,12 +7,6 @@ import (
 	"go.opentelemetry.io/otel/log"
 )
 
-func handleErr(err error, s string) {
-	if err != nil {
-		panic(fmt.Sprintf("%s: %v", s, err))
-	}
-}
-
 func Attribute(k string, v any) attribute.KeyValue {
 	return parseAttribute(k, v)
 }
@@ -70,3 +64,10 @@ func parseLogAttribute(key string, value any) log.KeyValue {
 	}
 	return attr
 }
+
+// withDefaults returns the options New derives from Config followed by the
+// caller's options, so that the caller's options win where they overlap.
+// It is the single merge rule of every exporter and provider.
+func withDefaults[T any](defaults []T, opts ...T) []T {
+	return append(defaults, opts...)
+}
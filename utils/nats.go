@@ -5,34 +5,41 @@ import (
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
-
-	"github.com/rorua/otelemetry"
 )
 
 func GetJetstreamTraceContext(ctx context.Context, msg jetstream.Msg) context.Context {
+	return global.GetJetstreamTraceContext(ctx, msg)
+}
+
+func GetNatsTraceContext(ctx context.Context, msg nats.Msg) context.Context {
+	return global.GetNatsTraceContext(ctx, msg)
+}
+
+func SetNatsHeaderTraceContext(ctx context.Context) nats.Header {
+	return global.SetNatsHeaderTraceContext(ctx)
+}
+
+func (p Propagation) GetJetstreamTraceContext(ctx context.Context, msg jetstream.Msg) context.Context {
 	var headers = make(map[string]string)
 	for k, _ := range msg.Headers() {
 		headers[k] = msg.Headers().Get(k)
 	}
 
-	return otelemetry.Extract(ctx, headers)
+	return p.extract(ctx, headers)
 }
 
-func GetNatsTraceContext(ctx context.Context, msg nats.Msg) context.Context {
+func (p Propagation) GetNatsTraceContext(ctx context.Context, msg nats.Msg) context.Context {
 	var headers = make(map[string]string)
 	for k, _ := range msg.Header {
 		headers[k] = msg.Header.Get(k)
 	}
 
-	return otelemetry.Extract(ctx, headers)
+	return p.extract(ctx, headers)
 }
 
-func SetNatsHeaderTraceContext(ctx context.Context) nats.Header {
-	carrier := propagation.MapCarrier{}
-	propagator := otel.GetTextMapPropagator()
-	propagator.Inject(ctx, carrier)
+func (p Propagation) SetNatsHeaderTraceContext(ctx context.Context) nats.Header {
+	carrier := make(map[string]string)
+	p.inject(ctx, carrier)
 
 	var header = make(nats.Header)
 	for key, value := range carrier {
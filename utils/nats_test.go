@@ -6,8 +6,12 @@ import (
 
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rorua/otelemetry"
 )
 
 func TestExtractsNatsTraceContextFromValidHeaders(t *testing.T) {
@@ -61,3 +65,46 @@ func TestInjectsTraceContextIntoNatsHeaders(t *testing.T) {
 	assert.Equal(t, inCarrier["traceparent"], headers.Get("traceparent"))
 	assert.Equal(t, inCarrier["tracestate"], headers.Get("tracestate"))
 }
+
+func TestNatsHeadersUseConfiguredPropagators(t *testing.T) {
+	tel, err := otelemetry.New(otelemetry.Config{
+		Service:     otelemetry.Service{Name: "test-service"},
+		Propagators: []string{"b3multi"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "publish")
+	defer span.End()
+
+	header := SetNatsHeaderTraceContext(ctx)
+	assert.Equal(t, span.Span().SpanContext().TraceID().String(), header.Get("x-b3-traceid"))
+	assert.Empty(t, header.Get("traceparent"))
+
+	remote := trace.SpanContextFromContext(GetNatsTraceContext(context.Background(), nats.Msg{Header: header}))
+	assert.Equal(t, span.Span().SpanContext().TraceID(), remote.TraceID())
+}
+
+func TestNatsHeadersWithIsolatedTelemetry(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tel, err := otelemetry.New(otelemetry.Config{
+		Service:     otelemetry.Service{Name: "test-service"},
+		Isolated:    true,
+		Propagators: []string{"b3"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "publish")
+	defer span.End()
+
+	helpers := WithTelemetry(tel)
+	header := helpers.SetNatsHeaderTraceContext(ctx)
+	assert.NotEmpty(t, header.Get("b3"))
+	assert.Empty(t, header.Get("traceparent"))
+	assert.Empty(t, SetNatsHeaderTraceContext(ctx).Get("b3"), "the package helpers use the global propagator")
+
+	remote := trace.SpanContextFromContext(helpers.GetNatsTraceContext(context.Background(), nats.Msg{Header: header}))
+	assert.Equal(t, span.Span().SpanContext().TraceID(), remote.TraceID())
+}
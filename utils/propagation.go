@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/rorua/otelemetry"
+)
+
+// Propagation reads and writes the trace context of messages. The package
+// level helpers use the OpenTelemetry global propagator; WithTelemetry binds
+// them to the propagator of one Telemetry instance instead.
+type Propagation struct {
+	inject  func(ctx context.Context, kv map[string]string)
+	extract func(ctx context.Context, kv map[string]string) context.Context
+}
+
+// WithTelemetry returns the helpers using the propagator of tel, for
+// instances created with Config.Isolated.
+func WithTelemetry(tel otelemetry.Telemetry) Propagation {
+	return Propagation{inject: tel.Inject, extract: tel.Extract}
+}
+
+// global is used by the package level helpers.
+var global = Propagation{inject: otelemetry.Inject, extract: otelemetry.Extract}
@@ -4,26 +4,30 @@ import (
 	"context"
 
 	amqp "github.com/rabbitmq/amqp091-go"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 )
 
 func GetRabbitMQTraceContext(ctx context.Context, msg amqp.Delivery) context.Context {
-	carrier := propagation.MapCarrier{}
+	return global.GetRabbitMQTraceContext(ctx, msg)
+}
+
+func SetRabbitMQHeaderTraceContext(ctx context.Context) amqp.Table {
+	return global.SetRabbitMQHeaderTraceContext(ctx)
+}
+
+func (p Propagation) GetRabbitMQTraceContext(ctx context.Context, msg amqp.Delivery) context.Context {
+	carrier := make(map[string]string)
 	for k, v := range msg.Headers {
 		if str, ok := v.(string); ok {
 			carrier[k] = str
 		}
 	}
 
-	propagator := otel.GetTextMapPropagator()
-	return propagator.Extract(ctx, carrier)
+	return p.extract(ctx, carrier)
 }
 
-func SetRabbitMQHeaderTraceContext(ctx context.Context) amqp.Table {
-	carrier := propagation.MapCarrier{}
-	propagator := otel.GetTextMapPropagator()
-	propagator.Inject(ctx, carrier)
+func (p Propagation) SetRabbitMQHeaderTraceContext(ctx context.Context) amqp.Table {
+	carrier := make(map[string]string)
+	p.inject(ctx, carrier)
 
 	headers := amqp.Table{}
 	for k, v := range carrier {
@@ -6,8 +6,12 @@ import (
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rorua/otelemetry"
 )
 
 func TestExtractsTraceContextFromHeadersWithValidStringValues(t *testing.T) {
@@ -111,3 +115,21 @@ func TestInjectsTraceContextIntoHeadersWithMultipleKeys(t *testing.T) {
 	assert.Equal(t, msg.Headers["traceparent"], headers["traceparent"])
 	assert.Equal(t, msg.Headers["tracestate"], headers["tracestate"])
 }
+
+func TestRabbitMQHeadersUseConfiguredPropagators(t *testing.T) {
+	tel, err := otelemetry.New(otelemetry.Config{
+		Service:     otelemetry.Service{Name: "test-service"},
+		Propagators: []string{"jaeger"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tel.Shutdown(context.Background()) })
+
+	ctx, span := tel.Trace().StartSpan(context.Background(), "publish")
+	defer span.End()
+
+	headers := SetRabbitMQHeaderTraceContext(ctx)
+	assert.Contains(t, headers, "uber-trace-id")
+
+	remote := trace.SpanContextFromContext(GetRabbitMQTraceContext(context.Background(), amqp.Delivery{Headers: headers}))
+	assert.Equal(t, span.Span().SpanContext().TraceID(), remote.TraceID())
+}
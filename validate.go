@@ -0,0 +1,132 @@
+package otelemetry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Validate checks the configuration for missing or inconsistent values.
+// The returned error wraps ErrInvalidConfig and lists every problem found.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Service.Name == "" {
+		errs = append(errs, errors.New("service name is required"))
+	}
+
+	for _, e := range []struct {
+		signal   string
+		exporter Exporter
+	}{
+		{SignalTraces, c.TracerOptions.Exporter},
+		{SignalMetrics, c.MetricOptions.Exporter},
+		{SignalLogs, c.LoggerOptions.Exporter},
+	} {
+		if err := e.exporter.validate(e.signal); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, c.validateCollectors()...)
+
+	errs = append(errs, c.TracerOptions.Sampler.validate("sampler")...)
+	errs = append(errs, c.TracerOptions.TailSampling.validate()...)
+
+	if c.MetricOptions.PeriodicInterval < 0 {
+		errs = append(errs, fmt.Errorf("metric periodic interval %v must not be negative", c.MetricOptions.PeriodicInterval))
+	}
+
+	errs = append(errs, validatePropagators(c.Propagators)...)
+
+	if c.TracerOptions.Sampler.uses(SamplerConsistentProbability) && len(c.Propagators) > 0 && !slices.Contains(c.Propagators, "tracecontext") {
+		errs = append(errs, errors.New(`consistent probability sampling requires the "tracecontext" propagator`))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+}
+
+// validateCollectors validates the effective collector of every signal
+// exported with ExporterOTLP. Problems of the shared collector are reported once; problems that
+// only exist because of a per-signal override are prefixed with the signal.
+func (c Config) validateCollectors() []error {
+	traces, metrics, logs := c.exporters()
+	signals := []struct {
+		name     string
+		exporter Exporter
+		override *Collector
+	}{
+		{SignalTraces, traces, c.TracerOptions.Collector},
+		{SignalMetrics, metrics, c.MetricOptions.Collector},
+		{SignalLogs, logs, c.LoggerOptions.Collector},
+	}
+
+	var (
+		errs []error
+		seen = make(map[string]bool)
+	)
+	for _, s := range signals {
+		if s.exporter != ExporterOTLP {
+			continue
+		}
+		for _, err := range c.Collector.merge(s.override).validate() {
+			if s.override != nil {
+				err = fmt.Errorf("%s: %w", s.name, err)
+			}
+			if !seen[err.Error()] {
+				seen[err.Error()] = true
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func (c Collector) validate() []error {
+	var errs []error
+
+	if c.Host == "" {
+		errs = append(errs, errors.New("collector host is required"))
+	}
+
+	if c.Port == "" {
+		errs = append(errs, errors.New("collector port is required"))
+	} else if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("collector port %q is not a valid port number", c.Port))
+	}
+
+	if c.TLS != nil {
+		errs = append(errs, c.TLS.validate()...)
+	}
+
+	switch c.Protocol {
+	case "", ProtocolGRPC, ProtocolHTTPProtobuf, ProtocolHTTPJSON:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported collector protocol %q", c.Protocol))
+	}
+
+	errs = append(errs, c.ExportPolicy.validate()...)
+
+	return errs
+}
+
+func validatePropagators(names []string) []error {
+	var errs []error
+
+	for _, name := range names {
+		if _, ok := propagators[name]; !ok {
+			errs = append(errs, fmt.Errorf("unsupported propagator %q", name))
+		}
+		if name == "none" && len(names) > 1 {
+			errs = append(errs, errors.New(`propagator "none" cannot be combined with other propagators`))
+		}
+	}
+
+	return errs
+}